@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/faradayfan/sygkro/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the user configuration",
+	Long: `Manage the user configuration stored in $XDG_CONFIG_HOME/sygkro/config.yaml
+(~/.config/sygkro/config.yaml by default). Keys have the form <section>.<name>:
+	default_inputs.<input>   default answer for an input of any template
+	aliases.<alias>          template reference used for --template <alias>
+	default_flags.<flag>     default value for a flag of project create, project link and template new
+	`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// readUserConfig reads the user config from its default location.
+func readUserConfig() (*config.UserConfig, error) {
+	path, err := config.UserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return config.ReadUserConfig(path)
+}
+
+// loadUserConfig reads the user config and applies its default flags to the
+// flags of cmd that were not set on the command line.
+func loadUserConfig(cmd *cobra.Command) (*config.UserConfig, error) {
+	userConfig, err := readUserConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range userConfig.DefaultFlags {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return nil, fmt.Errorf("invalid default for flag --%s in %s: %w", name, userConfig.Path, err)
+		}
+	}
+
+	return userConfig, nil
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var configGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Prints a value of the user configuration",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userConfig, err := readUserConfig()
+		if err != nil {
+			return err
+		}
+
+		value, ok, err := userConfig.Get(args[0])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%s is not set", args[0])
+		}
+
+		fmt.Println(value)
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd)
+}
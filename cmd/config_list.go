@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the values of the user configuration",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userConfig, err := readUserConfig()
+		if err != nil {
+			return err
+		}
+
+		for _, line := range userConfig.List() {
+			fmt.Println(line)
+		}
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configListCmd)
+}
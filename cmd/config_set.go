@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var configSetCmd = &cobra.Command{
+	Use:   "set [key] [value]",
+	Short: "Sets a value of the user configuration",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userConfig, err := readUserConfig()
+		if err != nil {
+			return err
+		}
+
+		if err := userConfig.Set(args[0], args[1]); err != nil {
+			return err
+		}
+		if err := userConfig.Write(userConfig.Path); err != nil {
+			return fmt.Errorf("failed to write user config: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configSetCmd)
+}
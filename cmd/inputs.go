@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/faradayfan/sygkro/internal/config"
+	"github.com/faradayfan/sygkro/internal/inputs"
+	"github.com/spf13/cobra"
+)
+
+// addInputFlags registers the flags that control how template inputs are collected.
+func addInputFlags(cmd *cobra.Command) {
+	addPromptFlags(cmd)
+	cmd.Flags().StringArrayP("input", "i", nil, "Input value as key=value (repeatable); takes precedence over --inputs-file and the environment")
+	cmd.Flags().String("inputs-file", "", "YAML or JSON file of input values; takes precedence over the environment")
+}
+
+// addPromptFlags registers the flags that control prompting alone, for
+// commands that change input values with flags of their own, such as
+// --set of project reconfigure.
+func addPromptFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("quiet", "q", false, "Accepts default values for all inputs that are not supplied, without prompting the user")
+	cmd.Flags().Bool("no-input", false, "Never prompt; fails if an input is not supplied by --input, --inputs-file or SYGKRO_INPUT_<NAME>")
+}
+
+// collectInputs resolves the template inputs from --input flags, the answers
+// file and SYGKRO_INPUT_<NAME> environment variables, prompting for the rest
+// unless --quiet or --no-input is set. The default answers of the user config
+// replace the template's defaults. Values in stored, the inputs kept in a
+// project's sync config, take precedence over every other source, so only
+// inputs without a stored value are supplied or prompted for. stored is nil
+// when a project is first generated.
+func collectInputs(cmd *cobra.Command, templating *config.TemplatingConfig, stored map[string]any) (map[string]any, error) {
+	quietMode, err := cmd.Flags().GetBool("quiet")
+	if err != nil {
+		return nil, err
+	}
+	noInput, err := cmd.Flags().GetBool("no-input")
+	if err != nil {
+		return nil, err
+	}
+	// Commands registered with addPromptFlags have no --input and --inputs-file
+	var pairs []string
+	var answersFile string
+	if cmd.Flags().Lookup("input") != nil {
+		if pairs, err = cmd.Flags().GetStringArray("input"); err != nil {
+			return nil, err
+		}
+		if answersFile, err = cmd.Flags().GetString("inputs-file"); err != nil {
+			return nil, err
+		}
+	}
+
+	supplied, err := inputs.Supplied(templating, pairs, answersFile, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range stored {
+		supplied[name] = value
+	}
+
+	userConfig, err := readUserConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	prompter, err := inputPrompter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if prompter != nil && stored == nil {
+		fmt.Println("Please provide values for the following inputs:")
+	}
+
+	opts := inputs.Options{
+		Prompter:        prompter,
+		Supplied:        supplied,
+		RequireSupplied: noInput && !quietMode,
+		Defaults:        userConfig.DefaultInputs,
+	}
+
+	return inputs.Collect(templating, opts)
+}
+
+// inputPrompter returns a prompter on stdin, or nil when --quiet or
+// --no-input is set.
+func inputPrompter(cmd *cobra.Command) (*inputs.Prompter, error) {
+	quietMode, err := cmd.Flags().GetBool("quiet")
+	if err != nil {
+		return nil, err
+	}
+	noInput, err := cmd.Flags().GetBool("no-input")
+	if err != nil {
+		return nil, err
+	}
+	if quietMode || noInput {
+		return nil, nil
+	}
+	return inputs.NewPrompter(os.Stdin, os.Stdout), nil
+}
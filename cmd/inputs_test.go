@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func TestProjectReconfigureCmd_OnlySetChangesValues(t *testing.T) {
+	for _, name := range []string{"input", "inputs-file"} {
+		if projectReconfigureCmd.Flags().Lookup(name) != nil {
+			t.Errorf("reconfigure should not take --%s, which stored values would override", name)
+		}
+	}
+	for _, name := range []string{"set", "quiet", "no-input", "jobs"} {
+		if projectReconfigureCmd.Flags().Lookup(name) == nil {
+			t.Errorf("reconfigure should take --%s", name)
+		}
+	}
+}
+
+func TestCollectInputs_WithoutInputFlags(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("SYGKRO_INPUT_REGION", "eu")
+
+	cmd := &cobra.Command{}
+	addPromptFlags(cmd)
+	if err := cmd.ParseFlags([]string{"--quiet"}); err != nil {
+		t.Fatal(err)
+	}
+	templating := &config.TemplatingConfig{Inputs: config.Inputs{
+		{Name: "port", Default: "80"},
+		{Name: "region", Default: "us"},
+		{Name: "owner", Default: "platform"},
+	}}
+
+	got, err := collectInputs(cmd, templating, map[string]any{"port": "8080"})
+	if err != nil {
+		t.Fatalf("collectInputs failed: %v", err)
+	}
+	want := map[string]any{"port": "8080", "region": "eu", "owner": "platform"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("collectInputs = %v, want %v", got, want)
+	}
+}
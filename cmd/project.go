@@ -16,3 +16,14 @@ var projectCmd = &cobra.Command{
 		cmd.Help()
 	},
 }
+
+// addStrictFlag registers --strict, which makes references to undefined
+// inputs an error, as the strict template option does.
+func addStrictFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("strict", false, "Fail on references to undefined inputs, listing every one of them")
+}
+
+// addJobsFlag registers --jobs, the number of files rendered at once.
+func addJobsFlag(cmd *cobra.Command) {
+	cmd.Flags().IntP("jobs", "j", 0, "Number of files to render at once (default: one per CPU)")
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,6 +9,7 @@ import (
 	"github.com/faradayfan/sygkro/internal/config"
 	"github.com/faradayfan/sygkro/internal/engine"
 	"github.com/faradayfan/sygkro/internal/git"
+	"github.com/faradayfan/sygkro/internal/inputs"
 	"github.com/spf13/cobra"
 )
 
@@ -26,6 +26,11 @@ var projectCreateCmd = &cobra.Command{
 	`,
 	Args: cobra.ExactArgs(0),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		userConfig, err := loadUserConfig(cmd)
+		if err != nil {
+			return err
+		}
+
 		targetDir, err := cmd.Flags().GetString("target")
 		if err != nil {
 			return err
@@ -35,12 +40,23 @@ var projectCreateCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		templateRef = userConfig.ResolveTemplate(templateRef)
 
 		gitRef, err := cmd.Flags().GetString("git-ref")
 		if err != nil {
 			return err
 		}
 
+		strict, err := cmd.Flags().GetBool("strict")
+		if err != nil {
+			return err
+		}
+
+		jobs, err := cmd.Flags().GetInt("jobs")
+		if err != nil {
+			return err
+		}
+
 		templateResults, err := git.GetTemplateDir(templateRef, gitRef)
 		if err != nil {
 			return err
@@ -63,34 +79,12 @@ var projectCreateCmd = &cobra.Command{
 			return fmt.Errorf("failed to read template config file: %w", err)
 		}
 
-		inputs := make(map[string]string)
-		quietMode, err := cmd.Flags().GetBool("quiet")
+		templateInputs, err := collectInputs(cmd, &tmplConfig.Templating, nil)
 		if err != nil {
 			return err
 		}
 
-		if quietMode {
-			for key, defaultVal := range tmplConfig.Templating.Inputs {
-				inputs[key] = defaultVal
-			}
-		} else {
-			reader := bufio.NewReader(os.Stdin)
-			fmt.Println("Please provide values for the following inputs:")
-			for key, defaultVal := range tmplConfig.Templating.Inputs {
-				fmt.Printf("%s (default: %s): ", key, defaultVal)
-				userInput, err := reader.ReadString('\n')
-				if err != nil {
-					return fmt.Errorf("error reading input for %s: %w", key, err)
-				}
-				userInput = strings.TrimSpace(userInput)
-				if userInput == "" {
-					userInput = defaultVal
-				}
-				inputs[key] = userInput
-			}
-		}
-
-		renderedProjectDir, err := engine.RenderString("{{ .slug }}", inputs)
+		renderedProjectDir, err := engine.RenderString("{{ .slug }}", templateInputs)
 		if err != nil {
 			return fmt.Errorf("failed to render project directory name: %w", err)
 		}
@@ -103,7 +97,13 @@ var projectCreateCmd = &cobra.Command{
 			return fmt.Errorf("failed to create destination directory: %w", err)
 		}
 
-		if err := engine.ProcessTemplateDir(expectedSubDir, destination, inputs, tmplConfig.Options); err != nil {
+		if strict {
+			tmplConfig.SetStrict()
+		}
+
+		if err := engine.ProcessTemplateDirJobs(expectedSubDir, destination, tmplConfig.RenderData(templateInputs), tmplConfig.Options, jobs); err != nil {
+			// Don't leave a half-written project behind
+			os.RemoveAll(destination)
 			return fmt.Errorf("failed to process template subdirectory: %w", err)
 		}
 
@@ -120,7 +120,7 @@ var projectCreateCmd = &cobra.Command{
 				TemplateVersion:     templateResults.CommitSHA,
 				TemplateTrackingRef: trackingRefString,
 			},
-			Inputs: inputs,
+			Inputs: inputs.WithoutSecrets(&tmplConfig.Templating, templateInputs),
 		}
 		syncConfigFilePath := filepath.Join(destination, config.SyncConfigFileName)
 		if err := syncConfig.Write(syncConfigFilePath); err != nil {
@@ -137,6 +137,8 @@ func init() {
 	projectCreateCmd.Flags().StringP("template", "s", "", "Path or Git repo reference to the template (required)")
 	projectCreateCmd.Flags().StringP("target", "t", ".", "Target directory for the new project")
 	projectCreateCmd.Flags().StringP("git-ref", "r", "", "Git reference (branch, tag, or commit SHA) to use for the template")
-	projectCreateCmd.Flags().BoolP("quiet", "q", false, "Accepts default values for all inputs without prompting the user")
+	addStrictFlag(projectCreateCmd)
+	addJobsFlag(projectCreateCmd)
+	addInputFlags(projectCreateCmd)
 	projectCreateCmd.MarkFlagRequired("template")
 }
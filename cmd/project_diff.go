@@ -17,8 +17,10 @@ and the latest version. This previews what 'project sync' will bring in, showing
 only template-side changes (not project customizations).
 	1. Reads the sygkro.sync.yaml file to get the template source and inputs.
 	2. Clones the template repository with full history.
-	3. Renders the template at both the old (synced) and new (latest) versions.
-	4. Outputs the diff between the two rendered versions.
+	3. Prepares the inputs as 'project sync' does: resolves secret inputs, applies pending
+	   migrations and asks for inputs new in the latest version.
+	4. Renders the template at both the old (synced) and new (latest) versions, at the same time.
+	5. Outputs the diff between the two rendered versions.
 	`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		syncFilePath := cmd.Flag("config").Value.String()
@@ -38,7 +40,27 @@ only template-side changes (not project customizations).
 		}
 		defer templateDir.Cleanup()
 
-		diff, err := git.ComputeTemplateDiff(templateDir.Path, syncConfig.Source.TemplateVersion, syncConfig)
+		jobs, err := cmd.Flags().GetInt("jobs")
+		if err != nil {
+			return err
+		}
+
+		oldTemplateDir := ""
+		if oldVersion := syncConfig.Source.TemplateVersion; oldVersion != "" {
+			dir, cleanup, err := git.GitWorktree(templateDir.Path, oldVersion)
+			if err != nil {
+				return fmt.Errorf("failed to checkout old template version %s: %w", oldVersion, err)
+			}
+			defer cleanup()
+			oldTemplateDir = dir
+		}
+
+		prepared, err := prepareSyncInputs(cmd, syncConfig, templateDir.Path, oldTemplateDir)
+		if err != nil {
+			return err
+		}
+
+		diff, err := git.ComputeTemplateDiff(templateDir.Path, oldTemplateDir, prepared.stored, prepared.render, jobs)
 		if err != nil {
 			return fmt.Errorf("failed to compute diff: %w", err)
 		}
@@ -56,4 +78,6 @@ func init() {
 	projectCmd.AddCommand(projectDiffCmd)
 	projectDiffCmd.Flags().StringP("config", "c", config.SyncConfigFileName, "Path to the sync config file")
 	projectDiffCmd.Flags().StringP("git-ref", "r", "", "Git reference to use (branch, tag, or commit SHA)")
+	addJobsFlag(projectDiffCmd)
+	addInputFlags(projectDiffCmd)
 }
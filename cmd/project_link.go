@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,6 +8,7 @@ import (
 
 	"github.com/faradayfan/sygkro/internal/config"
 	"github.com/faradayfan/sygkro/internal/git"
+	"github.com/faradayfan/sygkro/internal/inputs"
 	"github.com/spf13/cobra"
 )
 
@@ -17,7 +17,7 @@ func init() {
 	projectLinkCmd.Flags().StringP("template", "s", "", "Path or Git repo reference to the template (required)")
 	projectLinkCmd.Flags().StringP("target", "t", ".", "Target directory for the project to be linked to the template")
 	projectLinkCmd.Flags().StringP("git-ref", "r", "", "Git reference (branch, tag, or commit SHA) to use for the template")
-	projectLinkCmd.Flags().BoolP("quiet", "q", false, "Accepts default values for all inputs without prompting the user")
+	addInputFlags(projectLinkCmd)
 	projectLinkCmd.MarkFlagRequired("template")
 }
 
@@ -32,6 +32,11 @@ var projectLinkCmd = &cobra.Command{
 		4. Writes a sygkro.sync.yaml file to track the template source and inputs used.
 	`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		userConfig, err := loadUserConfig(cmd)
+		if err != nil {
+			return err
+		}
+
 		targetDir, err := cmd.Flags().GetString("target")
 		if err != nil {
 			return err
@@ -45,6 +50,7 @@ var projectLinkCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		templateRef = userConfig.ResolveTemplate(templateRef)
 
 		gitRef, err := cmd.Flags().GetString("git-ref")
 		if err != nil {
@@ -73,33 +79,11 @@ var projectLinkCmd = &cobra.Command{
 			return fmt.Errorf("failed to read template config file: %w", err)
 		}
 
-		inputs := make(map[string]string)
-		quietMode, err := cmd.Flags().GetBool("quiet")
+		templateInputs, err := collectInputs(cmd, &tmplConfig.Templating, nil)
 		if err != nil {
 			return err
 		}
 
-		if quietMode {
-			for key, defaultVal := range tmplConfig.Templating.Inputs {
-				inputs[key] = defaultVal
-			}
-		} else {
-			reader := bufio.NewReader(os.Stdin)
-			fmt.Println("Please provide values for the following inputs:")
-			for key, defaultVal := range tmplConfig.Templating.Inputs {
-				fmt.Printf("%s (default: %s): ", key, defaultVal)
-				userInput, err := reader.ReadString('\n')
-				if err != nil {
-					return fmt.Errorf("error reading input for %s: %w", key, err)
-				}
-				userInput = strings.TrimSpace(userInput)
-				if userInput == "" {
-					userInput = defaultVal
-				}
-				inputs[key] = userInput
-			}
-		}
-
 		trackingRef := strings.Split(templateResults.HeadRef, "/")
 		var trackingRefString string = ""
 		if len(trackingRef) > 0 {
@@ -113,7 +97,7 @@ var projectLinkCmd = &cobra.Command{
 				TemplateVersion:     templateResults.CommitSHA,
 				TemplateTrackingRef: trackingRefString,
 			},
-			Inputs: inputs,
+			Inputs: inputs.WithoutSecrets(&tmplConfig.Templating, templateInputs),
 		}
 		syncConfigFilePath := filepath.Join(targetDir, config.SyncConfigFileName)
 		if err := syncConfig.Write(syncConfigFilePath); err != nil {
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/faradayfan/sygkro/internal/config"
+	"github.com/faradayfan/sygkro/internal/git"
+	"github.com/faradayfan/sygkro/internal/inputs"
+	"github.com/faradayfan/sygkro/internal/vfs"
+	"github.com/spf13/cobra"
+)
+
+var projectReconfigureCmd = &cobra.Command{
+	Use:   "reconfigure --set key=value",
+	Short: "Changes input values of a project and re-renders it with a 3-way merge",
+	Long: `Changes input values of a project and re-renders it using 3-way merge.
+		1. Reads the sygkro.sync.yaml file to get the template source and inputs.
+		2. Clones the template repository and checks the synced template version out into a worktree.
+		3. Applies the --set values to the stored inputs, asks for inputs that the new values
+		   enable, and validates the result.
+		4. Renders the template with the old inputs (base) and the new inputs (theirs).
+		5. Moves project files whose rendered path changed, so they are merged rather than duplicated.
+		6. Performs a 3-way merge for each file (base=old inputs, ours=project, theirs=new inputs).
+		7. Updates the sygkro.sync.yaml file with the new inputs.
+	`,
+	Args: cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		syncFilePath := cmd.Flag("config").Value.String()
+		syncConfig, err := config.ReadSyncConfig(syncFilePath)
+		if err != nil {
+			return err
+		}
+
+		pairs, err := cmd.Flags().GetStringArray("set")
+		if err != nil {
+			return err
+		}
+		if len(pairs) == 0 {
+			return fmt.Errorf("nothing to change; pass at least one --set key=value")
+		}
+
+		templateDir, err := git.GetTemplateDirForSync(syncConfig.Source.TemplatePath, syncConfig.Source.TemplateTrackingRef)
+		if err != nil {
+			return fmt.Errorf("failed to clone template repository: %w", err)
+		}
+		defer templateDir.Cleanup()
+
+		// Render the synced version so that only the input changes show up in the merge.
+		// It is checked out into a worktree, which leaves a local template repo as it is
+		renderDir := templateDir.Path
+		if version := syncConfig.Source.TemplateVersion; version != "" {
+			dir, cleanup, err := git.GitWorktree(templateDir.Path, version)
+			if err != nil {
+				return fmt.Errorf("failed to checkout template version %s: %w", version, err)
+			}
+			defer cleanup()
+			renderDir = dir
+		}
+
+		templateConfig, err := config.ReadTemplateConfig(filepath.Join(renderDir, config.TemplateConfigFileName))
+		if err != nil {
+			return fmt.Errorf("failed to read template config: %w", err)
+		}
+
+		prompter, err := inputPrompter(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Secret inputs are never stored, so they come from the environment or a fresh prompt
+		oldInputs, err := inputs.ResolveSecrets(&templateConfig.Templating, syncConfig.Inputs, os.LookupEnv, prompter)
+		if err != nil {
+			return err
+		}
+
+		changes, err := inputs.ParsePairs(&templateConfig.Templating, pairs)
+		if err != nil {
+			return err
+		}
+		changedInputs := make(map[string]any, len(oldInputs)+len(changes))
+		for name, value := range oldInputs {
+			changedInputs[name] = value
+		}
+		for name, value := range changes {
+			changedInputs[name] = value
+		}
+
+		newInputs, err := collectInputs(cmd, &templateConfig.Templating, changedInputs)
+		if err != nil {
+			return err
+		}
+
+		jobs, err := cmd.Flags().GetInt("jobs")
+		if err != nil {
+			return err
+		}
+
+		base := vfs.Memory()
+		if err := git.RenderTemplate(renderDir, base, oldInputs, git.RenderOptions{Jobs: jobs}); err != nil {
+			return fmt.Errorf("failed to render template with the old inputs: %w", err)
+		}
+
+		strict, err := cmd.Flags().GetBool("strict")
+		if err != nil {
+			return err
+		}
+		theirs := vfs.Memory()
+		if err := git.RenderTemplate(renderDir, theirs, newInputs, git.RenderOptions{Strict: strict, Jobs: jobs}); err != nil {
+			return fmt.Errorf("failed to render template with the new inputs: %w", err)
+		}
+
+		// Files whose names depend on changed inputs are moved, in the project and in
+		// the base, so the merge treats them as the same file
+		renames, err := git.RenamedPaths(renderDir, oldInputs, newInputs)
+		if err != nil {
+			return err
+		}
+		if _, err := git.MoveRenamedFilesFS(base, renames); err != nil {
+			return err
+		}
+		project := vfs.OS(".")
+		moved, err := git.MoveRenamedFilesFS(project, renames)
+		if err != nil {
+			return err
+		}
+		for _, oldPath := range moved {
+			fmt.Printf("  renamed: %s -> %s\n", oldPath, renames[oldPath])
+		}
+
+		mergeResult, err := git.ThreeWayMergeFS(base, project, theirs, templateConfig.Options)
+		if err != nil {
+			return fmt.Errorf("failed to merge: %w", err)
+		}
+
+		if err := git.ApplyMergeFS(project, base, theirs, mergeResult, templateConfig.Options); err != nil {
+			return fmt.Errorf("failed to apply merge: %w", err)
+		}
+		printMergeSummary(mergeResult)
+
+		syncConfig.Inputs = inputs.WithoutSecrets(&templateConfig.Templating, newInputs)
+		if err := syncConfig.Write(syncFilePath); err != nil {
+			return fmt.Errorf("failed to write sync config: %w", err)
+		}
+
+		if mergeResult.HasConflict {
+			fmt.Println("Reconfigure completed with conflicts. Review .sygkro-conflict files.")
+		} else {
+			fmt.Println("Reconfigure completed successfully.")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	projectCmd.AddCommand(projectReconfigureCmd)
+	projectReconfigureCmd.Flags().StringP("config", "c", config.SyncConfigFileName, "Path to the sync config file")
+	projectReconfigureCmd.Flags().StringArray("set", nil, "New input value as key=value (repeatable)")
+	addStrictFlag(projectReconfigureCmd)
+	addJobsFlag(projectReconfigureCmd)
+	// --set is the only way to change a value, so --input and --inputs-file are not taken
+	addPromptFlags(projectReconfigureCmd)
+}
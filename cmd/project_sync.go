@@ -3,9 +3,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 
 	"github.com/faradayfan/sygkro/internal/config"
 	"github.com/faradayfan/sygkro/internal/git"
+	"github.com/faradayfan/sygkro/internal/inputs"
+	"github.com/faradayfan/sygkro/internal/vfs"
 	"github.com/spf13/cobra"
 )
 
@@ -15,10 +19,15 @@ var projectSyncCmd = &cobra.Command{
 	Long: `Syncs a project to a template using 3-way merge.
 		1. Reads the sygkro.sync.yaml file to get the template source and inputs.
 		2. Clones the template repository with full history.
-		3. Renders the template at both the old and new versions.
-		4. Performs a 3-way merge for each file (base=old template, ours=project, theirs=new template).
-		5. Clean merges update project files. Conflicts create .sygkro-conflict files.
-		6. Updates the sygkro.sync.yaml file with the new template version.
+		3. Checks the old version out into a worktree and reads secret inputs from
+		   SYGKRO_INPUT_<NAME> or a prompt.
+		4. Applies the input migrations added since the old version, asks for inputs that are
+		   new in this version and validates the result.
+		5. Renders the template at the old version with the stored inputs, formatted by the new
+		   version's formatters, and at the new version, at the same time.
+		6. Performs a 3-way merge for each file (base=old template, ours=project, theirs=new template).
+		7. Clean merges update project files. Conflicts create .sygkro-conflict files.
+		8. Updates the sygkro.sync.yaml file with the new template version and migrated inputs.
 	`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		syncFilePath := cmd.Flag("config").Value.String()
@@ -41,66 +50,99 @@ var projectSyncCmd = &cobra.Command{
 		}
 		defer templateDir.Cleanup()
 
-		// Render the NEW template (at HEAD)
-		theirsTmpDir, err := os.MkdirTemp("", "sygkro-theirs-*")
+		jobs, err := cmd.Flags().GetInt("jobs")
 		if err != nil {
-			return fmt.Errorf("failed to create temp dir: %w", err)
+			return err
 		}
-		defer os.RemoveAll(theirsTmpDir)
 
-		if err := git.RenderTemplateAtPath(templateDir.Path, theirsTmpDir, syncConfig.Inputs); err != nil {
-			return fmt.Errorf("failed to render new template: %w", err)
+		oldTemplateDir := ""
+		if oldVersion != "" {
+			// The old version is checked out next to the new one, so that both can be rendered at once
+			dir, cleanup, err := git.GitWorktree(templateDir.Path, oldVersion)
+			if err != nil {
+				return fmt.Errorf("failed to checkout old template version %s: %w", oldVersion, err)
+			}
+			defer cleanup()
+			oldTemplateDir = dir
 		}
 
-		// Render the OLD template (at previously synced version)
-		baseTmpDir, err := os.MkdirTemp("", "sygkro-base-*")
+		prepared, err := prepareSyncInputs(cmd, syncConfig, templateDir.Path, oldTemplateDir)
 		if err != nil {
-			return fmt.Errorf("failed to create temp dir: %w", err)
+			return err
 		}
-		defer os.RemoveAll(baseTmpDir)
+		for _, m := range prepared.pending {
+			fmt.Printf("  migrated inputs: %s\n", m.ID)
+		}
+		for _, name := range prepared.dropped {
+			fmt.Printf("  dropped input: %s (no longer declared by the template)\n", name)
+		}
+		storedInputs, renderInputs, newTemplateConfig := prepared.stored, prepared.render, prepared.newConfig
 
-		if oldVersion != "" {
-			if err := git.GitCheckout(templateDir.Path, oldVersion); err != nil {
-				return fmt.Errorf("failed to checkout old template version %s: %w", oldVersion, err)
-			}
-			if err := git.RenderTemplateAtPath(templateDir.Path, baseTmpDir, syncConfig.Inputs); err != nil {
-				return fmt.Errorf("failed to render old template: %w", err)
+		// Both renders stay in memory; only the project is on disk
+		base := vfs.Memory()
+		theirs := vfs.Memory()
+
+		strict, err := cmd.Flags().GetBool("strict")
+		if err != nil {
+			return err
+		}
+
+		var renders []func() error
+		if oldTemplateDir != "" {
+			// Render the OLD template (at previously synced version) with the inputs as stored
+			renders = append(renders, func() error {
+				// Format the base like the new template formats its files, so that a
+				// new or changed formatter is not mistaken for a template change
+				opts := git.RenderOptions{Jobs: jobs, FormatAs: newTemplateConfig}
+				if err := git.RenderTemplate(oldTemplateDir, base, storedInputs, opts); err != nil {
+					return fmt.Errorf("failed to render old template: %w", err)
+				}
+				return nil
+			})
+		}
+		// Render the NEW template (at HEAD) at the same time
+		renders = append(renders, func() error {
+			if err := git.RenderTemplate(templateDir.Path, theirs, renderInputs, git.RenderOptions{Strict: strict, Jobs: jobs}); err != nil {
+				return fmt.Errorf("failed to render new template: %w", err)
 			}
+			return nil
+		})
+		if err := git.RenderConcurrently(renders...); err != nil {
+			return err
 		}
 
 		// 3-way merge: base (old template) vs ours (project) vs theirs (new template)
-		mergeResult, err := git.ThreeWayMerge(baseTmpDir, ".", theirsTmpDir)
+		project := vfs.OS(".")
+		mergeResult, err := git.ThreeWayMergeFS(base, project, theirs, newTemplateConfig.Options)
 		if err != nil {
 			return fmt.Errorf("failed to merge: %w", err)
 		}
 
 		// Check if there are any changes
 		if len(mergeResult.Files) == 0 {
+			// Migrated inputs and answers to new inputs still need to be written back
+			newStoredInputs := inputs.WithoutSecrets(&newTemplateConfig.Templating, renderInputs)
+			if !reflect.DeepEqual(newStoredInputs, syncConfig.Inputs) {
+				syncConfig.Source.TemplateVersion = templateDir.CommitSHA
+				syncConfig.Inputs = newStoredInputs
+				if err := syncConfig.Write(syncFilePath); err != nil {
+					return fmt.Errorf("failed to write sync config: %w", err)
+				}
+			}
 			fmt.Println("No differences found.")
 			return nil
 		}
 
 		// Apply merge results
-		if err := git.ApplyMerge(".", baseTmpDir, theirsTmpDir, mergeResult); err != nil {
+		if err := git.ApplyMergeFS(project, base, theirs, mergeResult, newTemplateConfig.Options); err != nil {
 			return fmt.Errorf("failed to apply merge: %w", err)
 		}
 
-		// Print summary
-		for _, f := range mergeResult.Files {
-			switch f.Status {
-			case git.MergeClean:
-				fmt.Printf("  updated: %s\n", f.RelPath)
-			case git.MergeConflict:
-				fmt.Printf("  conflict: %s (see %s)\n", f.RelPath, f.ConflictPath)
-			case git.MergeNewFile:
-				fmt.Printf("  added: %s\n", f.RelPath)
-			case git.MergeDeletedFile:
-				fmt.Printf("  deleted in template (kept): %s\n", f.RelPath)
-			}
-		}
+		printMergeSummary(mergeResult)
 
-		// Update sync config
+		// Update sync config, keeping secrets out of it
 		syncConfig.Source.TemplateVersion = templateDir.CommitSHA
+		syncConfig.Inputs = inputs.WithoutSecrets(&newTemplateConfig.Templating, renderInputs)
 		if err := syncConfig.Write(syncFilePath); err != nil {
 			return fmt.Errorf("failed to write sync config: %w", err)
 		}
@@ -115,8 +157,88 @@ var projectSyncCmd = &cobra.Command{
 	},
 }
 
+// printMergeSummary prints one line for every file the merge changed.
+func printMergeSummary(result *git.MergeResult) {
+	for _, f := range result.Files {
+		switch f.Status {
+		case git.MergeClean:
+			if f.Mode != 0 {
+				fmt.Printf("  updated: %s (mode %04o)\n", f.RelPath, f.Mode)
+			} else {
+				fmt.Printf("  updated: %s\n", f.RelPath)
+			}
+		case git.MergeConflict:
+			fmt.Printf("  conflict: %s (see %s)\n", f.RelPath, f.ConflictPath)
+		case git.MergeNewFile:
+			fmt.Printf("  added: %s\n", f.RelPath)
+		case git.MergeDeletedFile:
+			fmt.Printf("  deleted in template (kept): %s\n", f.RelPath)
+		}
+	}
+}
+
+// syncInputs are the inputs of a project prepared for rendering the
+// previously synced and the new version of its template.
+type syncInputs struct {
+	stored    map[string]any     // the stored inputs with their secrets, for the old version
+	render    map[string]any     // the migrated inputs with the answers to new inputs
+	pending   []config.Migration // the migrations applied to the stored inputs
+	dropped   []string           // the stored inputs the new version no longer declares
+	newConfig *config.TemplateConfig
+}
+
+// prepareSyncInputs reads the template config of the new version in
+// templateDir and of the old version in oldTemplateDir, which is empty when
+// the project was never synced. It resolves the secret inputs of the old
+// version, migrates the stored inputs to the new version and asks for the
+// inputs it adds, as project sync renders them and project diff previews.
+func prepareSyncInputs(cmd *cobra.Command, syncConfig *config.SyncConfig, templateDir, oldTemplateDir string) (*syncInputs, error) {
+	prompter, err := inputPrompter(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	prepared := &syncInputs{stored: syncConfig.Inputs}
+	var oldMigrations []config.Migration
+	if oldTemplateDir != "" {
+		oldTemplateConfig, err := config.ReadTemplateConfig(filepath.Join(oldTemplateDir, config.TemplateConfigFileName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read old template config: %w", err)
+		}
+		oldMigrations = oldTemplateConfig.Templating.Migrations
+
+		// Secret inputs are never stored, so they come from the environment or a fresh prompt
+		prepared.stored, err = inputs.ResolveSecrets(&oldTemplateConfig.Templating, syncConfig.Inputs, os.LookupEnv, prompter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	prepared.newConfig, err = config.ReadTemplateConfig(filepath.Join(templateDir, config.TemplateConfigFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template config: %w", err)
+	}
+
+	// Migrate the stored inputs to the NEW template, then ask for the inputs it adds
+	prepared.pending = inputs.PendingMigrations(prepared.newConfig.Templating.Migrations, oldMigrations)
+	migratedInputs, dropped, err := inputs.Migrate(&prepared.newConfig.Templating, prepared.pending, prepared.stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate stored inputs: %w", err)
+	}
+	prepared.dropped = dropped
+
+	prepared.render, err = collectInputs(cmd, &prepared.newConfig.Templating, migratedInputs)
+	if err != nil {
+		return nil, fmt.Errorf("inputs are not valid for the new template version: %w", err)
+	}
+	return prepared, nil
+}
+
 func init() {
 	projectCmd.AddCommand(projectSyncCmd)
 	projectSyncCmd.Flags().StringP("config", "c", config.SyncConfigFileName, "Path to the sync config file")
 	projectSyncCmd.Flags().StringP("git-ref", "r", "", "Git reference to use (branch, tag, or commit SHA)")
+	addStrictFlag(projectSyncCmd)
+	addJobsFlag(projectSyncCmd)
+	addInputFlags(projectSyncCmd)
 }
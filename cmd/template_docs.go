@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/faradayfan/sygkro/internal/config"
+	"github.com/faradayfan/sygkro/internal/inputs"
+	"github.com/spf13/cobra"
+)
+
+var templateDocsCmd = &cobra.Command{
+	Use:   "docs [template-dir]",
+	Short: "Prints a Markdown reference of a template's inputs",
+	Long: `Prints a Markdown reference of a template's inputs.
+	1. Reads the sygkro.template.yaml file from the template directory (defaults to the current directory).
+	2. Writes a table of inputs per section, in the order they are prompted.
+	`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		templateDir := "."
+		if len(args) > 0 {
+			templateDir = args[0]
+		}
+
+		tmplConfig, err := config.ReadTemplateConfig(filepath.Join(templateDir, config.TemplateConfigFileName))
+		if err != nil {
+			return fmt.Errorf("failed to read template config file: %w", err)
+		}
+
+		return inputs.WriteMarkdown(os.Stdout, &tmplConfig.Templating)
+	},
+}
+
+func init() {
+	templateCmd.AddCommand(templateDocsCmd)
+}
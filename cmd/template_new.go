@@ -21,12 +21,44 @@ var templateNewCmd = &cobra.Command{
 	`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		userConfig, err := loadUserConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		// The generated author input defaults to the user's own default answer
+		author := any("Your Name")
+		if userAuthor, ok := userConfig.DefaultInputs["author"]; ok {
+			author = userAuthor
+		}
+
 		templateName := args[0]
-		templateInputs := map[string]string{
-			"name":        "my-project",
-			"slug":        "my-project",
-			"description": "A new project created by sygkro",
-			"author":      "Your Name",
+		templateInputs := config.Inputs{
+			{
+				Name:        "name",
+				Type:        config.InputTypeString,
+				Description: "Human readable name of the project",
+				Default:     "My Project",
+				Required:    true,
+			},
+			{
+				Name:        "slug",
+				Type:        config.InputTypeString,
+				Description: "Directory and package name of the project",
+				Default:     "{{ .name | kebab }}",
+				Required:    true,
+				Format:      "kebab-case",
+			},
+			{
+				Name:    "description",
+				Type:    config.InputTypeString,
+				Default: "A new project created by sygkro",
+			},
+			{
+				Name:    "author",
+				Type:    config.InputTypeString,
+				Default: author,
+			},
 		}
 
 		templateDir := filepath.Join(templateName)
@@ -53,7 +85,7 @@ var templateNewCmd = &cobra.Command{
 			},
 		}
 
-		err := templateConfig.Write(configFilePath)
+		err = templateConfig.Write(configFilePath)
 		if err != nil {
 			return fmt.Errorf("failed to marshal config: %w", err)
 		}
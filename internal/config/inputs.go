@@ -0,0 +1,345 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InputType is the declared type of a template input.
+type InputType string
+
+const (
+	InputTypeString InputType = "string"
+	InputTypeBool   InputType = "bool"
+	InputTypeInt    InputType = "int"
+	InputTypeChoice InputType = "choice"
+	InputTypeList   InputType = "list"
+	InputTypeMap    InputType = "map"
+)
+
+// InputSpec describes a single template input.
+//
+// An input may be declared in its short form, where the value is the default
+// string (e.g. `name: my-project`), or in its full form as a mapping with a type,
+// description, prompt label and so on.
+type InputSpec struct {
+	Name        string    `yaml:"name,omitempty"`
+	Type        InputType `yaml:"type,omitempty"`
+	Description string    `yaml:"description,omitempty"`
+	Prompt      string    `yaml:"prompt,omitempty"`
+	Required    bool      `yaml:"required,omitempty"`
+	Default     any       `yaml:"default,omitempty"`
+	Choices     []string  `yaml:"choices,omitempty"`
+
+	// Secret inputs are read without echo and never written to the sync config.
+	Secret bool `yaml:"secret,omitempty"`
+
+	// When is an expression over earlier answers; the input is only asked for,
+	// and only passed to templates, when it evaluates to true.
+	When string `yaml:"when,omitempty"`
+
+	// Validation constraints, checked by Validate.
+	Format    string   `yaml:"format,omitempty"`
+	Pattern   string   `yaml:"pattern,omitempty"`
+	MinLength *int     `yaml:"min_length,omitempty"`
+	MaxLength *int     `yaml:"max_length,omitempty"`
+	Min       *int     `yaml:"min,omitempty"`
+	Max       *int     `yaml:"max,omitempty"`
+	Allowed   []string `yaml:"allowed,omitempty"`
+}
+
+// inputSpecFields is used to decode the full form of an InputSpec without
+// recursing into UnmarshalYAML.
+type inputSpecFields InputSpec
+
+// UnmarshalYAML accepts both the short (scalar default) and full (mapping) forms.
+func (s *InputSpec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = InputSpec{Type: InputTypeString, Default: node.Value}
+		return nil
+	}
+
+	var fields inputSpecFields
+	if err := node.Decode(&fields); err != nil {
+		return err
+	}
+	*s = InputSpec(fields)
+	if s.Type == "" {
+		s.Type = InputTypeString
+	}
+
+	switch s.Type {
+	case InputTypeString, InputTypeBool, InputTypeInt, InputTypeList, InputTypeMap:
+	case InputTypeChoice:
+		if len(s.Choices) == 0 {
+			return fmt.Errorf("line %d: choice input must declare choices", node.Line)
+		}
+	default:
+		return fmt.Errorf("line %d: unknown input type %q", node.Line, s.Type)
+	}
+
+	if s.Format != "" {
+		if _, ok := namedFormats[s.Format]; !ok {
+			return fmt.Errorf("line %d: unknown format %q", node.Line, s.Format)
+		}
+	}
+
+	return nil
+}
+
+// Label returns the text used when prompting for the input.
+func (s *InputSpec) Label() string {
+	if s.Prompt != "" {
+		return s.Prompt
+	}
+	return s.Name
+}
+
+// Inputs is an ordered list of input declarations. It is declared in YAML
+// either as a sequence of full-form inputs, each with a name, or in the older
+// mapping form keyed by name; both keep the order of the file.
+type Inputs []*InputSpec
+
+// UnmarshalYAML accepts both the sequence and the mapping forms.
+func (in *Inputs) UnmarshalYAML(node *yaml.Node) error {
+	var result Inputs
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			spec := &InputSpec{}
+			if err := node.Content[i+1].Decode(spec); err != nil {
+				return err
+			}
+			spec.Name = node.Content[i].Value
+			result = append(result, spec)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind != yaml.MappingNode {
+				return fmt.Errorf("line %d: inputs declared as a list must be mappings with a name", item.Line)
+			}
+			spec := &InputSpec{}
+			if err := item.Decode(spec); err != nil {
+				return err
+			}
+			if spec.Name == "" {
+				return fmt.Errorf("line %d: input is missing a name", item.Line)
+			}
+			result = append(result, spec)
+		}
+	default:
+		return fmt.Errorf("line %d: inputs must be a list or a mapping", node.Line)
+	}
+
+	*in = result
+	return nil
+}
+
+// Parse converts a raw string, as typed by a user, into a value of the input's type.
+func (s *InputSpec) Parse(raw string) (any, error) {
+	raw = strings.TrimSpace(raw)
+
+	switch s.Type {
+	case InputTypeBool:
+		switch strings.ToLower(raw) {
+		case "y", "yes", "on":
+			return true, nil
+		case "n", "no", "off":
+			return false, nil
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean (use true/false or yes/no)", raw)
+		}
+		return b, nil
+
+	case InputTypeInt:
+		i, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+		return i, nil
+
+	case InputTypeChoice:
+		if contains(s.Choices, raw) {
+			return raw, nil
+		}
+		return nil, fmt.Errorf("%q is not one of: %s", raw, strings.Join(s.Choices, ", "))
+
+	case InputTypeList:
+		list := []any{}
+		if raw == "" {
+			return list, nil
+		}
+		for _, item := range strings.Split(raw, ",") {
+			list = append(list, strings.TrimSpace(item))
+		}
+		return list, nil
+
+	case InputTypeMap:
+		m := map[string]any{}
+		if raw == "" {
+			return m, nil
+		}
+		for _, pair := range strings.Split(raw, ",") {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("%q is not a key=value pair", strings.TrimSpace(pair))
+			}
+			m[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		return m, nil
+
+	default:
+		return raw, nil
+	}
+}
+
+// Coerce converts a value decoded from YAML or JSON (or a raw string) into a
+// value of the input's type.
+func (s *InputSpec) Coerce(value any) (any, error) {
+	if value == nil {
+		return s.Zero(), nil
+	}
+	if str, ok := value.(string); ok {
+		return s.Parse(str)
+	}
+
+	switch s.Type {
+	case InputTypeBool:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case InputTypeInt:
+		switch v := value.(type) {
+		case int:
+			return v, nil
+		case int64:
+			return int(v), nil
+		case uint64:
+			return int(v), nil
+		case float64:
+			if v == float64(int(v)) {
+				return int(v), nil
+			}
+		}
+	case InputTypeList:
+		switch v := value.(type) {
+		case []any:
+			return v, nil
+		case []string:
+			list := make([]any, 0, len(v))
+			for _, item := range v {
+				list = append(list, item)
+			}
+			return list, nil
+		}
+	case InputTypeMap:
+		switch v := value.(type) {
+		case map[string]any:
+			return v, nil
+		case map[string]string:
+			m := make(map[string]any, len(v))
+			for key, item := range v {
+				m[key] = item
+			}
+			return m, nil
+		}
+	default:
+		return s.Parse(fmt.Sprint(value))
+	}
+
+	return nil, fmt.Errorf("%v is not a valid %s", value, s.Type)
+}
+
+// DefaultValue returns the input's default coerced to its type.
+func (s *InputSpec) DefaultValue() (any, error) {
+	return s.Coerce(s.Default)
+}
+
+// Zero returns the empty value for the input's type.
+func (s *InputSpec) Zero() any {
+	switch s.Type {
+	case InputTypeBool:
+		return false
+	case InputTypeInt:
+		return 0
+	case InputTypeList:
+		return []any{}
+	case InputTypeMap:
+		return map[string]any{}
+	default:
+		return ""
+	}
+}
+
+// IsEmpty reports whether value should be treated as "not provided" for the
+// purpose of the required check.
+func IsEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// Unset reports whether value, as supplied for the input by an environment
+// variable or an answers file, stands for no value at all, so that the
+// input's default or a prompt applies: nil, or a blank string for a bool, int
+// or choice input, which have no empty value of their own.
+func (s *InputSpec) Unset(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+		switch s.Type {
+		case InputTypeBool, InputTypeInt:
+			return true
+		case InputTypeChoice:
+			return !contains(s.Choices, "")
+		}
+	}
+	return false
+}
+
+// FormatValue renders a typed value back into the string form accepted by Parse.
+func FormatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ",")
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(v))
+		for _, key := range keys {
+			pairs = append(pairs, fmt.Sprintf("%s=%v", key, v[key]))
+		}
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
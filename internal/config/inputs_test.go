@@ -0,0 +1,183 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestInputSpec_UnmarshalShortAndFullForms(t *testing.T) {
+	doc := `
+name: my-project
+use_docker:
+  type: bool
+  description: Build a Docker image
+  default: true
+port:
+  type: int
+  default: 8080
+env:
+  type: choice
+  choices: [dev, prod]
+  default: dev
+services:
+  type: list
+  default: [api, worker]
+`
+	var specs map[string]*InputSpec
+	if err := yaml.Unmarshal([]byte(doc), &specs); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	if got := specs["name"]; got.Type != InputTypeString || got.Default != "my-project" {
+		t.Errorf("short form: got %+v", got)
+	}
+	if got := specs["use_docker"]; got.Type != InputTypeBool || got.Default != true || got.Description != "Build a Docker image" {
+		t.Errorf("bool input: got %+v", got)
+	}
+
+	want := map[string]any{
+		"name":       "my-project",
+		"use_docker": true,
+		"port":       8080,
+		"env":        "dev",
+		"services":   []any{"api", "worker"},
+	}
+	for name, wantVal := range want {
+		got, err := specs[name].DefaultValue()
+		if err != nil {
+			t.Fatalf("DefaultValue(%s) failed: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, wantVal) {
+			t.Errorf("DefaultValue(%s) = %#v, want %#v", name, got, wantVal)
+		}
+	}
+}
+
+func TestInputSpec_UnmarshalRejectsBadSpecs(t *testing.T) {
+	cases := map[string]string{
+		"unknown type":       "x:\n  type: float\n",
+		"choice w/o choices": "x:\n  type: choice\n",
+	}
+	for name, doc := range cases {
+		var specs map[string]*InputSpec
+		if err := yaml.Unmarshal([]byte(doc), &specs); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestInputSpec_Parse(t *testing.T) {
+	cases := []struct {
+		spec    InputSpec
+		raw     string
+		want    any
+		wantErr bool
+	}{
+		{InputSpec{Type: InputTypeString}, " hello ", "hello", false},
+		{InputSpec{Type: InputTypeBool}, "yes", true, false},
+		{InputSpec{Type: InputTypeBool}, "false", false, false},
+		{InputSpec{Type: InputTypeBool}, "maybe", nil, true},
+		{InputSpec{Type: InputTypeInt}, "42", 42, false},
+		{InputSpec{Type: InputTypeInt}, "4.2", nil, true},
+		{InputSpec{Type: InputTypeChoice, Choices: []string{"a", "b"}}, "b", "b", false},
+		{InputSpec{Type: InputTypeChoice, Choices: []string{"a", "b"}}, "c", nil, true},
+		{InputSpec{Type: InputTypeList}, "api, worker", []any{"api", "worker"}, false},
+		{InputSpec{Type: InputTypeMap}, "a=1, b=2", map[string]any{"a": "1", "b": "2"}, false},
+		{InputSpec{Type: InputTypeMap}, "a", nil, true},
+	}
+
+	for _, tc := range cases {
+		got, err := tc.spec.Parse(tc.raw)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Parse(%s, %q) error = %v, wantErr %v", tc.spec.Type, tc.raw, err, tc.wantErr)
+			continue
+		}
+		if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Parse(%s, %q) = %#v, want %#v", tc.spec.Type, tc.raw, got, tc.want)
+		}
+	}
+}
+
+func TestInputSpec_Unset(t *testing.T) {
+	cases := []struct {
+		spec  InputSpec
+		value any
+		want  bool
+	}{
+		{InputSpec{Type: InputTypeBool}, "", true},
+		{InputSpec{Type: InputTypeBool}, " ", true},
+		{InputSpec{Type: InputTypeBool}, nil, true},
+		{InputSpec{Type: InputTypeBool}, false, false},
+		{InputSpec{Type: InputTypeInt}, "", true},
+		{InputSpec{Type: InputTypeChoice, Choices: []string{"a", "b"}}, "", true},
+		{InputSpec{Type: InputTypeChoice, Choices: []string{"", "a"}}, "", false},
+		{InputSpec{Type: InputTypeString}, "", false},
+		{InputSpec{Type: InputTypeList}, "", false},
+	}
+
+	for _, tc := range cases {
+		if got := tc.spec.Unset(tc.value); got != tc.want {
+			t.Errorf("Unset(%s, %#v) = %v, want %v", tc.spec.Type, tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestFormatValue_RoundTripsThroughParse(t *testing.T) {
+	spec := InputSpec{Type: InputTypeMap}
+	value := map[string]any{"b": "2", "a": "1"}
+
+	formatted := FormatValue(value)
+	if formatted != "a=1,b=2" {
+		t.Errorf("FormatValue = %q, want %q", formatted, "a=1,b=2")
+	}
+
+	parsed, err := spec.Parse(formatted)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, value) {
+		t.Errorf("round trip = %#v, want %#v", parsed, value)
+	}
+}
+
+func TestInputs_UnmarshalKeepsDeclarationOrder(t *testing.T) {
+	mapForm := `
+zeta: z
+alpha:
+  type: bool
+middle: m
+`
+	seqForm := `
+- name: zeta
+  default: z
+- name: alpha
+  type: bool
+- name: middle
+  default: m
+`
+	for label, doc := range map[string]string{"map": mapForm, "sequence": seqForm} {
+		var inputs Inputs
+		if err := yaml.Unmarshal([]byte(doc), &inputs); err != nil {
+			t.Fatalf("%s form: unmarshal failed: %v", label, err)
+		}
+		var names []string
+		for _, spec := range inputs {
+			names = append(names, spec.Name)
+		}
+		if !reflect.DeepEqual(names, []string{"zeta", "alpha", "middle"}) {
+			t.Errorf("%s form: names = %v", label, names)
+		}
+		if inputs[1].Type != InputTypeBool || inputs[2].Default != "m" {
+			t.Errorf("%s form: unexpected specs %+v %+v", label, inputs[1], inputs[2])
+		}
+	}
+}
+
+func TestInputs_UnmarshalRejectsUnnamedListEntries(t *testing.T) {
+	var inputs Inputs
+	if err := yaml.Unmarshal([]byte("- type: bool\n"), &inputs); err == nil {
+		t.Error("expected error for input without a name")
+	}
+}
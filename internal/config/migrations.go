@@ -0,0 +1,84 @@
+package config
+
+import "fmt"
+
+// Migration updates the inputs stored by projects generated from an earlier
+// version of the template. Exactly one operation is set:
+//
+//   - rename moves a stored value to a new input name
+//   - drop removes a stored value
+//   - set_default stores a value for an input that has none
+//   - compute stores the result of a template rendered against the stored inputs
+type Migration struct {
+	ID         string      `yaml:"id"`
+	Rename     *RenameStep `yaml:"rename,omitempty"`
+	Drop       string      `yaml:"drop,omitempty"`
+	SetDefault *ValueStep  `yaml:"set_default,omitempty"`
+	Compute    *ValueStep  `yaml:"compute,omitempty"`
+}
+
+// RenameStep renames the stored input From to To.
+type RenameStep struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// ValueStep assigns Value to the stored input Input.
+type ValueStep struct {
+	Input string `yaml:"input"`
+	Value any    `yaml:"value"`
+}
+
+// check reports a migration without an id or without exactly one valid operation.
+func (m *Migration) check() error {
+	if m.ID == "" {
+		return fmt.Errorf("migration has no id")
+	}
+
+	ops := 0
+	if m.Rename != nil {
+		ops++
+		if m.Rename.From == "" || m.Rename.To == "" {
+			return fmt.Errorf("migration %s: rename needs both from and to", m.ID)
+		}
+	}
+	if m.Drop != "" {
+		ops++
+	}
+	if m.SetDefault != nil {
+		ops++
+		if m.SetDefault.Input == "" {
+			return fmt.Errorf("migration %s: set_default needs an input", m.ID)
+		}
+	}
+	if m.Compute != nil {
+		ops++
+		if m.Compute.Input == "" {
+			return fmt.Errorf("migration %s: compute needs an input", m.ID)
+		}
+		if _, ok := m.Compute.Value.(string); !ok {
+			return fmt.Errorf("migration %s: compute value must be a template string", m.ID)
+		}
+	}
+	if ops != 1 {
+		return fmt.Errorf("migration %s: expected exactly one of rename, drop, set_default or compute", m.ID)
+	}
+
+	return nil
+}
+
+// checkMigrations reports invalid migrations and ids used more than once.
+func (t *TemplatingConfig) checkMigrations() error {
+	seen := make(map[string]bool)
+	for i := range t.Migrations {
+		m := &t.Migrations[i]
+		if err := m.check(); err != nil {
+			return err
+		}
+		if seen[m.ID] {
+			return fmt.Errorf("migration %s is declared more than once", m.ID)
+		}
+		seen[m.ID] = true
+	}
+	return nil
+}
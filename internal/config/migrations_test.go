@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadTemplateConfig_Migrations(t *testing.T) {
+	doc := `
+name: basic
+templating:
+  inputs:
+    - name: name
+  migrations:
+    - id: rename-project-name
+      rename: {from: project_name, to: name}
+    - id: drop-travis
+      drop: use_travis
+    - id: default-license
+      set_default: {input: license, value: MIT}
+    - id: module-path
+      compute: {input: module, value: "github.com/acme/{{ .name }}"}
+`
+	path := filepath.Join(t.TempDir(), TemplateConfigFileName)
+	if err := os.WriteFile(path, []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ReadTemplateConfig(path)
+	if err != nil {
+		t.Fatalf("ReadTemplateConfig failed: %v", err)
+	}
+
+	migrations := cfg.Templating.Migrations
+	if len(migrations) != 4 {
+		t.Fatalf("expected 4 migrations, got %d", len(migrations))
+	}
+	if migrations[0].Rename == nil || migrations[0].Rename.From != "project_name" || migrations[0].Rename.To != "name" {
+		t.Errorf("rename = %+v", migrations[0].Rename)
+	}
+	if migrations[1].Drop != "use_travis" {
+		t.Errorf("drop = %q", migrations[1].Drop)
+	}
+	if migrations[2].SetDefault == nil || migrations[2].SetDefault.Value != "MIT" {
+		t.Errorf("set_default = %+v", migrations[2].SetDefault)
+	}
+	if migrations[3].Compute == nil || migrations[3].Compute.Input != "module" {
+		t.Errorf("compute = %+v", migrations[3].Compute)
+	}
+}
+
+func TestTemplatingConfig_CheckMigrationsRejectsBadMigrations(t *testing.T) {
+	cases := map[string][]Migration{
+		"missing id":     {{Drop: "x"}},
+		"no operation":   {{ID: "a"}},
+		"two operations": {{ID: "a", Drop: "x", SetDefault: &ValueStep{Input: "y", Value: 1}}},
+		"half rename":    {{ID: "a", Rename: &RenameStep{From: "x"}}},
+		"non-string":     {{ID: "a", Compute: &ValueStep{Input: "x", Value: 3}}},
+		"duplicate id":   {{ID: "a", Drop: "x"}, {ID: "a", Drop: "y"}},
+	}
+	for name, migrations := range cases {
+		templating := &TemplatingConfig{Migrations: migrations}
+		if err := templating.checkMigrations(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
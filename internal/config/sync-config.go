@@ -5,9 +5,9 @@ const (
 )
 
 type SyncConfig struct {
-	Path   string            `yaml:"-"` // ignore when serializing
-	Source SourceConfig      `yaml:"source"`
-	Inputs map[string]string `yaml:"inputs"`
+	Path   string         `yaml:"-"` // ignore when serializing
+	Source SourceConfig   `yaml:"source"`
+	Inputs map[string]any `yaml:"inputs"`
 }
 
 type SourceConfig struct {
@@ -16,7 +16,7 @@ func TestSyncConfig_WriteAndReadSyncConfig(t *testing.T) {
 			TemplateVersion:     "1.0.0",
 			TemplateTrackingRef: "main",
 		},
-		Inputs: map[string]string{"key": "value"},
+		Inputs: map[string]any{"key": "value", "enabled": true, "services": []any{"api", "worker"}},
 	}
 
 	// Write
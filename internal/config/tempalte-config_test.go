@@ -1,6 +1,7 @@
 package config
 
 import (
+	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
@@ -14,7 +15,11 @@ func TestTemplateConfig_WriteAndReadTemplateConfig(t *testing.T) {
 		Description: "A basic template",
 		Version:     "1.0.0",
 		Templating: TemplatingConfig{
-			Inputs: map[string]string{"foo": "bar"},
+			Inputs: Inputs{
+				{Name: "foo", Type: InputTypeString, Default: "bar"},
+				{Name: "enabled", Type: InputTypeBool, Description: "Enable the feature", Default: true},
+				{Name: "env", Type: InputTypeChoice, Choices: []string{"dev", "prod"}, Default: "dev", Required: true},
+			},
 		},
 		Options: &TemplateOptions{SkipRender: []string{"README.md"}},
 	}
@@ -35,3 +40,106 @@ func TestTemplateConfig_WriteAndReadTemplateConfig(t *testing.T) {
 		t.Errorf("TemplateConfig roundtrip mismatch: got %+v, want %+v", readCfg, original)
 	}
 }
+
+func TestReadTemplateConfig_GroupsAndLookup(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), TemplateConfigFileName)
+	doc := `name: grouped
+templating:
+  inputs:
+    - name: name
+      default: My App
+  groups:
+    - name: CI
+      help: Continuous integration settings
+      inputs:
+        - name: ci_provider
+          type: choice
+          choices: [github, gitlab]
+          default: github
+    - name: Deployment
+      inputs:
+        - name: replicas
+          type: int
+          default: 2
+`
+	if err := os.WriteFile(filePath, []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ReadTemplateConfig(filePath)
+	if err != nil {
+		t.Fatalf("ReadTemplateConfig failed: %v", err)
+	}
+
+	var names []string
+	for _, spec := range cfg.Templating.AllInputs() {
+		names = append(names, spec.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"name", "ci_provider", "replicas"}) {
+		t.Errorf("AllInputs order = %v", names)
+	}
+	if spec := cfg.Templating.Input("replicas"); spec == nil || spec.Type != InputTypeInt {
+		t.Errorf("Input(replicas) = %+v", spec)
+	}
+	if group := cfg.Templating.GroupOf("ci_provider"); group == nil || group.Help != "Continuous integration settings" {
+		t.Errorf("GroupOf(ci_provider) = %+v", group)
+	}
+	if group := cfg.Templating.GroupOf("name"); group != nil {
+		t.Errorf("GroupOf(name) = %+v, want nil", group)
+	}
+}
+
+func TestReadTemplateConfig_RejectsDuplicateInputs(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), TemplateConfigFileName)
+	doc := `name: dup
+templating:
+  inputs:
+    name: a
+  groups:
+    - name: Project
+      inputs:
+        - name: name
+`
+	if err := os.WriteFile(filePath, []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadTemplateConfig(filePath); err == nil {
+		t.Error("expected error for duplicate input")
+	}
+}
+
+func TestReadTemplateConfig_Delimiters(t *testing.T) {
+	cases := map[string]bool{
+		"options:\n  delimiters: [\"[[\", \"]]\"]\n":                         true,
+		"options:\n  file_delimiters:\n    \"charts/*\": [\"[[\", \"]]\"]\n": true,
+		"options:\n  delimiters: [\"[[\"]\n":                                 false,
+		"options:\n  file_delimiters:\n    \"charts/*\": [\"\", \"]]\"]\n":   false,
+	}
+	for doc, valid := range cases {
+		filePath := filepath.Join(t.TempDir(), TemplateConfigFileName)
+		if err := os.WriteFile(filePath, []byte("name: delims\n"+doc), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadTemplateConfig(filePath); (err == nil) != valid {
+			t.Errorf("ReadTemplateConfig(%q) error = %v, want valid %v", doc, err, valid)
+		}
+	}
+}
+
+func TestReadTemplateConfig_EOL(t *testing.T) {
+	cases := map[string]bool{
+		"options:\n  eol:\n    \"*.bat\": crlf\n    \"*.sh\": lf\n": true,
+		"options:\n  eol:\n    \"*.bat\": CRLF\n":                   false,
+		"options:\n  eol:\n    \"*.bat\": native\n":                 false,
+	}
+	for doc, valid := range cases {
+		filePath := filepath.Join(t.TempDir(), TemplateConfigFileName)
+		if err := os.WriteFile(filePath, []byte("name: eol\n"+doc), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadTemplateConfig(filePath); (err == nil) != valid {
+			t.Errorf("ReadTemplateConfig(%q) error = %v, want valid %v", doc, err, valid)
+		}
+	}
+}
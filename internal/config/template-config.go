@@ -1,5 +1,7 @@
 package config
 
+import "fmt"
+
 var (
 	TemplateConfigFileName = "sygkro.template.yaml"
 )
@@ -13,11 +15,180 @@ type TemplateConfig struct {
 }
 
 type TemplatingConfig struct {
-	Inputs map[string]string `yaml:"inputs"`
+	Inputs Inputs           `yaml:"inputs"`
+	Groups []InputGroup     `yaml:"groups,omitempty"`
+	Rules  []ValidationRule `yaml:"rules,omitempty"`
+	// Migrations are applied, in order, to the stored inputs of projects
+	// synced from a template version that did not declare them yet.
+	Migrations []Migration `yaml:"migrations,omitempty"`
+}
+
+// InputGroup is a named section of inputs, prompted together under a header.
+type InputGroup struct {
+	Name   string `yaml:"name"`
+	Help   string `yaml:"help,omitempty"`
+	Inputs Inputs `yaml:"inputs"`
 }
 
+// TemplateOptions select how files of the template are rendered. Paths are
+// matched with .gitignore-style patterns relative to the template directory.
 type TemplateOptions struct {
+	// Engine selects how files are rendered: "gotemplate" (the default) for
+	// Go templates, or "envsubst" for ${name} references.
+	Engine string `yaml:"engine,omitempty"`
+	// Strict makes references to undefined inputs an error instead of
+	// rendering "<no value>". Every undefined reference is reported at once.
+	Strict bool `yaml:"strict,omitempty"`
+	// SkipRender lists files copied without rendering.
 	SkipRender []string `yaml:"skip_render,omitempty"`
+	// Exclude lists files and directories that never reach the project, such
+	// as fixtures for the template's own tests. Patterns from a .sygkroignore
+	// file in the template directory are added after them.
+	Exclude []string `yaml:"exclude,omitempty"`
+	// IncludeIf maps a pattern to a condition over the inputs. Files and
+	// directories matching the pattern are only generated when it holds.
+	IncludeIf map[string]string `yaml:"include_if,omitempty"`
+	// Foreach maps a pattern to an expression over the inputs that yields a
+	// list. Files and directories matching the pattern are generated once per
+	// element, which is available to their names and contents as .item. The
+	// first pattern matching a path, in sorted order, applies.
+	Foreach map[string]string `yaml:"foreach,omitempty"`
+	// Delimiters replace "{{" and "}}" in every template file, e.g. ["[[", "]]"].
+	Delimiters []string `yaml:"delimiters,omitempty"`
+	// FileDelimiters maps a pattern to the delimiters of the files it matches,
+	// overriding Delimiters.
+	FileDelimiters map[string][]string `yaml:"file_delimiters,omitempty"`
+	// Formatters maps a pattern to the formatters run, in order, on the
+	// generated files it matches: "gofmt", "json", "trim_trailing_whitespace"
+	// or "final_newline". Unlike other patterns, these are matched against the
+	// path in the project, e.g. "*.go".
+	Formatters map[string][]string `yaml:"formatters,omitempty"`
+	// EOL maps a pattern to the line endings, "lf" or "crlf", of the
+	// generated files it matches, whatever those of the template file. Like
+	// Formatters, these are matched against the path in the project.
+	EOL map[string]string `yaml:"eol,omitempty"`
+}
+
+// SetStrict turns on strict mode for undefined inputs.
+func (t *TemplateConfig) SetStrict() {
+	if t.Options == nil {
+		t.Options = &TemplateOptions{}
+	}
+	t.Options.Strict = true
+}
+
+// SetFormatters replaces the formatters of the template with those of other.
+func (t *TemplateConfig) SetFormatters(other *TemplateConfig) {
+	if t.Options == nil {
+		t.Options = &TemplateOptions{}
+	}
+	t.Options.Formatters = nil
+	if other.Options != nil {
+		t.Options.Formatters = other.Options.Formatters
+	}
+}
+
+// RenderData returns the data the template is rendered with for values. In
+// strict mode, declared inputs that values lack, such as those turned off by
+// their when condition, are defined, so that only references to undeclared
+// names are errors.
+func (t *TemplateConfig) RenderData(values map[string]any) map[string]any {
+	if t.Options == nil || !t.Options.Strict {
+		return values
+	}
+	return t.Templating.WithZeroValues(values)
+}
+
+// check reports delimiters that are not a pair of non-empty strings and
+// unknown line endings.
+func (o *TemplateOptions) check() error {
+	if o == nil {
+		return nil
+	}
+	if o.Delimiters != nil {
+		if err := checkDelimiters(o.Delimiters); err != nil {
+			return fmt.Errorf("options.delimiters: %w", err)
+		}
+	}
+	for pattern, delims := range o.FileDelimiters {
+		if err := checkDelimiters(delims); err != nil {
+			return fmt.Errorf("options.file_delimiters %q: %w", pattern, err)
+		}
+	}
+	for pattern, eol := range o.EOL {
+		if eol != "lf" && eol != "crlf" {
+			return fmt.Errorf("options.eol %q: expected lf or crlf, got %q", pattern, eol)
+		}
+	}
+	return nil
+}
+
+func checkDelimiters(delims []string) error {
+	if len(delims) != 2 || delims[0] == "" || delims[1] == "" {
+		return fmt.Errorf("expected a left and a right delimiter, e.g. [\"[[\", \"]]\"]")
+	}
+	return nil
+}
+
+// WithZeroValues returns a copy of values in which every declared input
+// that values lack is set to the zero value of its type.
+func (t *TemplatingConfig) WithZeroValues(values map[string]any) map[string]any {
+	data := make(map[string]any, len(values))
+	for name, value := range values {
+		data[name] = value
+	}
+	for _, spec := range t.AllInputs() {
+		if _, ok := data[spec.Name]; !ok {
+			data[spec.Name] = spec.Zero()
+		}
+	}
+	return data
+}
+
+// AllInputs returns every declared input in prompt order: the ungrouped
+// inputs first, then the inputs of each group.
+func (t *TemplatingConfig) AllInputs() Inputs {
+	all := make(Inputs, 0, len(t.Inputs))
+	all = append(all, t.Inputs...)
+	for _, group := range t.Groups {
+		all = append(all, group.Inputs...)
+	}
+	return all
+}
+
+// Input returns the input with the given name, or nil.
+func (t *TemplatingConfig) Input(name string) *InputSpec {
+	for _, spec := range t.AllInputs() {
+		if spec.Name == name {
+			return spec
+		}
+	}
+	return nil
+}
+
+// GroupOf returns the group that declares the named input, or nil for an
+// ungrouped input.
+func (t *TemplatingConfig) GroupOf(name string) *InputGroup {
+	for i := range t.Groups {
+		for _, spec := range t.Groups[i].Inputs {
+			if spec.Name == name {
+				return &t.Groups[i]
+			}
+		}
+	}
+	return nil
+}
+
+// checkNames reports inputs declared more than once.
+func (t *TemplatingConfig) checkNames() error {
+	seen := make(map[string]bool)
+	for _, spec := range t.AllInputs() {
+		if seen[spec.Name] {
+			return fmt.Errorf("input %s is declared more than once", spec.Name)
+		}
+		seen[spec.Name] = true
+	}
+	return nil
 }
 
 func (s *TemplateConfig) Write(path string) error {
@@ -32,5 +203,15 @@ func ReadTemplateConfig(path string) (*TemplateConfig, error) {
 		return nil, err
 	}
 
+	if err := templateConfig.Templating.checkNames(); err != nil {
+		return nil, err
+	}
+	if err := templateConfig.Templating.checkMigrations(); err != nil {
+		return nil, err
+	}
+	if err := templateConfig.Options.check(); err != nil {
+		return nil, err
+	}
+
 	return templateConfig, nil
 }
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var (
+	UserConfigFileName = "config.yaml"
+)
+
+// UserConfig holds the settings of the user running sygkro, shared by every
+// template: default answers for inputs, short aliases for template references
+// and default values for command flags.
+type UserConfig struct {
+	Path          string            `yaml:"-"` // ignore when serializing
+	DefaultInputs map[string]any    `yaml:"default_inputs,omitempty"`
+	Aliases       map[string]string `yaml:"aliases,omitempty"`
+	DefaultFlags  map[string]string `yaml:"default_flags,omitempty"`
+}
+
+// userConfigSections are the sections of the user config addressable as
+// "<section>.<name>" keys by Get, Set and List.
+var userConfigSections = []string{"aliases", "default_flags", "default_inputs"}
+
+// UserConfigPath returns the location of the user config:
+// $XDG_CONFIG_HOME/sygkro/config.yaml, or ~/.config/sygkro/config.yaml when
+// XDG_CONFIG_HOME is not set.
+func UserConfigPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate the user config: %w", err)
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "sygkro", UserConfigFileName), nil
+}
+
+// ResolveTemplate returns the template reference an alias stands for, or ref
+// itself when it is not an alias.
+func (u *UserConfig) ResolveTemplate(ref string) string {
+	if target, ok := u.Aliases[ref]; ok {
+		return target
+	}
+	return ref
+}
+
+// Get returns the value of a "<section>.<name>" key, e.g. "aliases.go-svc".
+func (u *UserConfig) Get(key string) (string, bool, error) {
+	section, name, err := splitUserConfigKey(key)
+	if err != nil {
+		return "", false, err
+	}
+
+	switch section {
+	case "aliases":
+		value, ok := u.Aliases[name]
+		return value, ok, nil
+	case "default_flags":
+		value, ok := u.DefaultFlags[name]
+		return value, ok, nil
+	default:
+		value, ok := u.DefaultInputs[name]
+		return FormatValue(value), ok, nil
+	}
+}
+
+// Set assigns the value of a "<section>.<name>" key.
+func (u *UserConfig) Set(key, value string) error {
+	section, name, err := splitUserConfigKey(key)
+	if err != nil {
+		return err
+	}
+
+	switch section {
+	case "aliases":
+		if u.Aliases == nil {
+			u.Aliases = make(map[string]string)
+		}
+		u.Aliases[name] = value
+	case "default_flags":
+		if u.DefaultFlags == nil {
+			u.DefaultFlags = make(map[string]string)
+		}
+		u.DefaultFlags[name] = value
+	default:
+		if u.DefaultInputs == nil {
+			u.DefaultInputs = make(map[string]any)
+		}
+		u.DefaultInputs[name] = value
+	}
+	return nil
+}
+
+// List returns every setting as "<section>.<name>=<value>", sorted.
+func (u *UserConfig) List() []string {
+	var lines []string
+	for name, value := range u.Aliases {
+		lines = append(lines, "aliases."+name+"="+value)
+	}
+	for name, value := range u.DefaultFlags {
+		lines = append(lines, "default_flags."+name+"="+value)
+	}
+	for name, value := range u.DefaultInputs {
+		lines = append(lines, "default_inputs."+name+"="+FormatValue(value))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func splitUserConfigKey(key string) (string, string, error) {
+	section, name, ok := strings.Cut(key, ".")
+	if !ok || name == "" || !contains(userConfigSections, section) {
+		return "", "", fmt.Errorf("invalid key %q: expected one of %s followed by .<name>", key, strings.Join(userConfigSections, ", "))
+	}
+	return section, name, nil
+}
+
+func (u *UserConfig) Write(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return WriteYAML(path, u)
+}
+
+// ReadUserConfig reads the user config at path. A missing file is an empty config.
+func ReadUserConfig(path string) (*UserConfig, error) {
+	userConfig := &UserConfig{Path: path}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return userConfig, nil
+	}
+
+	if err := ReadYAML(path, userConfig); err != nil {
+		return nil, err
+	}
+	userConfig.Path = path
+
+	return userConfig, nil
+}
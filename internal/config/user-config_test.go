@@ -0,0 +1,83 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUserConfigPath_UsesXDGConfigHome(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+
+	path, err := UserConfigPath()
+	if err != nil {
+		t.Fatalf("UserConfigPath failed: %v", err)
+	}
+	if want := filepath.Join("/tmp/xdg", "sygkro", "config.yaml"); path != want {
+		t.Errorf("path = %q, want %q", path, want)
+	}
+}
+
+func TestReadUserConfig_MissingFileIsEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sygkro", UserConfigFileName)
+
+	userConfig, err := ReadUserConfig(path)
+	if err != nil {
+		t.Fatalf("ReadUserConfig failed: %v", err)
+	}
+	if userConfig.Path != path || len(userConfig.List()) != 0 {
+		t.Errorf("expected an empty config at %s, got %+v", path, userConfig)
+	}
+}
+
+func TestUserConfig_SetWriteAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sygkro", UserConfigFileName)
+	userConfig := &UserConfig{}
+	for key, value := range map[string]string{
+		"default_inputs.author": "Jane Doe",
+		"aliases.go-svc":        "gh:ourorg/go-service-template",
+		"default_flags.quiet":   "true",
+	} {
+		if err := userConfig.Set(key, value); err != nil {
+			t.Fatalf("Set(%s) failed: %v", key, err)
+		}
+	}
+	if err := userConfig.Write(path); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	readCfg, err := ReadUserConfig(path)
+	if err != nil {
+		t.Fatalf("ReadUserConfig failed: %v", err)
+	}
+
+	want := []string{
+		"aliases.go-svc=gh:ourorg/go-service-template",
+		"default_flags.quiet=true",
+		"default_inputs.author=Jane Doe",
+	}
+	if got := readCfg.List(); !reflect.DeepEqual(got, want) {
+		t.Errorf("List = %v, want %v", got, want)
+	}
+	if value, ok, err := readCfg.Get("aliases.go-svc"); err != nil || !ok || value != "gh:ourorg/go-service-template" {
+		t.Errorf("Get = %q, %v, %v", value, ok, err)
+	}
+	if got := readCfg.ResolveTemplate("go-svc"); got != "gh:ourorg/go-service-template" {
+		t.Errorf("ResolveTemplate(alias) = %q", got)
+	}
+	if got := readCfg.ResolveTemplate("gh:other/repo"); got != "gh:other/repo" {
+		t.Errorf("ResolveTemplate(non-alias) = %q", got)
+	}
+}
+
+func TestUserConfig_RejectsInvalidKeys(t *testing.T) {
+	userConfig := &UserConfig{}
+	for _, key := range []string{"author", "unknown.x", "aliases."} {
+		if err := userConfig.Set(key, "v"); err == nil {
+			t.Errorf("Set(%q): expected error", key)
+		}
+		if _, _, err := userConfig.Get(key); err == nil {
+			t.Errorf("Get(%q): expected error", key)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// namedFormats are the values accepted by the `format` field of an input.
+var namedFormats = map[string]*regexp.Regexp{
+	"kebab-case":  regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`),
+	"snake_case":  regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`),
+	"camelCase":   regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`),
+	"PascalCase":  regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`),
+	"lowercase":   regexp.MustCompile(`^[^A-Z]*$`),
+	"uppercase":   regexp.MustCompile(`^[^a-z]*$`),
+	"email":       regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`),
+	"semver":      regexp.MustCompile(`^v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`),
+	"identifier":  regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`),
+	"domain-name": regexp.MustCompile(`^([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,}$`),
+}
+
+// ValidationRule is a template-level rule evaluated against all input values.
+// Rule is a template expression that must render to "true" for the inputs to
+// be valid. When Input is set, the rule is reported against (and re-prompted
+// for) that input.
+type ValidationRule struct {
+	Input   string `yaml:"input,omitempty"`
+	Rule    string `yaml:"rule"`
+	Message string `yaml:"message,omitempty"`
+}
+
+// Violation describes a single failed validation.
+type Violation struct {
+	Input   string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Input == "" {
+		return v.Message
+	}
+	return fmt.Sprintf("%s: %s", v.Input, v.Message)
+}
+
+// ValidationError lists every violation found while validating a set of inputs.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Violations)+1)
+	lines = append(lines, fmt.Sprintf("%d input validation error(s):", len(e.Violations)))
+	for _, v := range e.Violations {
+		lines = append(lines, "  - "+v.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate checks a typed value against the input's constraints and returns a
+// message for every constraint it violates.
+func (s *InputSpec) Validate(value any) []string {
+	var problems []string
+
+	if IsEmpty(value) {
+		if s.Required {
+			problems = append(problems, "is required")
+		}
+		// Constraints only apply to values that were provided.
+		return problems
+	}
+
+	if s.Format != "" {
+		re, ok := namedFormats[s.Format]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown format %q (known formats: %s)", s.Format, strings.Join(FormatNames(), ", ")))
+		} else {
+			for _, str := range stringValues(value) {
+				if !re.MatchString(str) {
+					problems = append(problems, fmt.Sprintf("%q must be %s", str, s.Format))
+				}
+			}
+		}
+	}
+
+	if s.Pattern != "" {
+		re, err := regexp.Compile("^(?:" + s.Pattern + ")$")
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid pattern %q: %v", s.Pattern, err))
+		} else {
+			for _, str := range stringValues(value) {
+				if !re.MatchString(str) {
+					problems = append(problems, fmt.Sprintf("%q does not match pattern %s", str, s.Pattern))
+				}
+			}
+		}
+	}
+
+	if s.MinLength != nil || s.MaxLength != nil {
+		length, unit := valueLength(value)
+		if s.MinLength != nil && length < *s.MinLength {
+			problems = append(problems, fmt.Sprintf("must have at least %d %s", *s.MinLength, unit))
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			problems = append(problems, fmt.Sprintf("must have at most %d %s", *s.MaxLength, unit))
+		}
+	}
+
+	if n, ok := value.(int); ok {
+		if s.Min != nil && n < *s.Min {
+			problems = append(problems, fmt.Sprintf("must be at least %d", *s.Min))
+		}
+		if s.Max != nil && n > *s.Max {
+			problems = append(problems, fmt.Sprintf("must be at most %d", *s.Max))
+		}
+	}
+
+	if len(s.Allowed) > 0 {
+		for _, str := range stringValues(value) {
+			if !contains(s.Allowed, str) {
+				problems = append(problems, fmt.Sprintf("%q is not one of: %s", str, strings.Join(s.Allowed, ", ")))
+			}
+		}
+	}
+
+	return problems
+}
+
+// FormatNames returns the sorted names of the supported formats.
+func FormatNames() []string {
+	names := make([]string, 0, len(namedFormats))
+	for name := range namedFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// stringValues returns the string form of a scalar value, or of each element of a list.
+func stringValues(value any) []string {
+	switch v := value.(type) {
+	case []any:
+		strs := make([]string, 0, len(v))
+		for _, item := range v {
+			strs = append(strs, fmt.Sprint(item))
+		}
+		return strs
+	case map[string]any:
+		strs := make([]string, 0, len(v))
+		for _, item := range v {
+			strs = append(strs, fmt.Sprint(item))
+		}
+		sort.Strings(strs)
+		return strs
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// valueLength returns the length of a value and the unit it is measured in.
+func valueLength(value any) (int, string) {
+	switch v := value.(type) {
+	case []any:
+		return len(v), "items"
+	case map[string]any:
+		return len(v), "entries"
+	default:
+		return utf8.RuneCountInString(fmt.Sprint(v)), "characters"
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestInputSpec_Validate(t *testing.T) {
+	cases := []struct {
+		name      string
+		spec      InputSpec
+		value     any
+		wantCount int
+	}{
+		{"required empty", InputSpec{Required: true}, "", 1},
+		{"optional empty skips constraints", InputSpec{Pattern: "[a-z]+"}, "", 0},
+		{"kebab ok", InputSpec{Format: "kebab-case"}, "my-app", 0},
+		{"kebab bad", InputSpec{Format: "kebab-case"}, "My_App", 1},
+		{"pattern is anchored", InputSpec{Pattern: "[a-z]+"}, "abc1", 1},
+		{"min length", InputSpec{MinLength: intPtr(3)}, "ab", 1},
+		{"max length", InputSpec{MaxLength: intPtr(3)}, "abcd", 1},
+		{"list length", InputSpec{Type: InputTypeList, MinLength: intPtr(2)}, []any{"a"}, 1},
+		{"range low", InputSpec{Type: InputTypeInt, Min: intPtr(1), Max: intPtr(10)}, 0, 1},
+		{"range ok", InputSpec{Type: InputTypeInt, Min: intPtr(1), Max: intPtr(10)}, 5, 0},
+		{"allowed list items", InputSpec{Type: InputTypeList, Allowed: []string{"api", "web"}}, []any{"api", "db", "cache"}, 2},
+		{"multiple violations", InputSpec{Format: "kebab-case", MaxLength: intPtr(2)}, "ABC", 2},
+	}
+
+	for _, tc := range cases {
+		problems := tc.spec.Validate(tc.value)
+		if len(problems) != tc.wantCount {
+			t.Errorf("%s: got %d problems %v, want %d", tc.name, len(problems), problems, tc.wantCount)
+		}
+	}
+}
+
+func TestValidationError_ListsEveryViolation(t *testing.T) {
+	err := &ValidationError{Violations: []Violation{
+		{Input: "slug", Message: "must be kebab-case"},
+		{Input: "port", Message: "must be at least 1"},
+		{Message: "name and slug must differ"},
+	}}
+
+	msg := err.Error()
+	for _, want := range []string{"3 input validation error(s)", "- slug: must be kebab-case", "- port: must be at least 1", "- name and slug must differ"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error message %q does not contain %q", msg, want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// sniffLen is how much of a file is inspected to decide whether it is binary.
+const sniffLen = 8000
+
+// IsBinary reports whether content looks like binary data rather than text:
+// it contains a NUL byte, or it is not valid UTF-8 and its sniffed MIME type
+// is not a text type.
+func IsBinary(content []byte) bool {
+	sample := content
+	if len(sample) > sniffLen {
+		sample = sample[:sniffLen]
+	}
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+
+	valid := utf8.Valid(sample)
+	// A multi-byte character may have been cut off at the end of the sample
+	for i := 1; !valid && len(sample) < len(content) && i < utf8.UTFMax; i++ {
+		valid = utf8.Valid(sample[:len(sample)-i])
+	}
+	if valid {
+		return false
+	}
+
+	return !strings.HasPrefix(http.DetectContentType(sample), "text/")
+}
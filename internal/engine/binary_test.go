@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsBinary(t *testing.T) {
+	cases := map[string]struct {
+		content []byte
+		want    bool
+	}{
+		"text":            {[]byte("hello {{ .name }}\n"), false},
+		"utf-8":           {[]byte("héllo wörld ✓\n"), false},
+		"empty":           {nil, false},
+		"nul byte":        {[]byte("abc\x00def"), true},
+		"png":             {[]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), true},
+		"control bytes":   {[]byte{0x01, 0x02, 0xff, 0x80, 0x81}, true},
+		"latin-1 text":    {[]byte("caf\xe9 cr\xe8me\n"), false},
+		"cut multi-byte":  {append(bytes.Repeat([]byte("a"), sniffLen-1), []byte("✓")...), false},
+		"long plain text": {[]byte(strings.Repeat("line\n", 5000)), false},
+	}
+	for name, tc := range cases {
+		if got := IsBinary(tc.content); got != tc.want {
+			t.Errorf("%s: IsBinary = %v, want %v", name, got, tc.want)
+		}
+	}
+}
+
+func TestProcessTemplateDir_CopiesBinaryFiles(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	content := []byte("\x89PNG\r\n\x1a\n\x00{{ .not_a_template\xff")
+	if err := os.WriteFile(filepath.Join(src, "logo.png"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ProcessTemplateDir(src, dst, map[string]any{}, nil); err != nil {
+		t.Fatalf("ProcessTemplateDir failed: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dst, "logo.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("binary file was modified: %q", got)
+	}
+}
@@ -0,0 +1,31 @@
+package engine
+
+import "strings"
+
+// Expression turns a condition such as `.use_docker` or `eq .env "prod"` into
+// a template. Conditions already written as templates are returned unchanged.
+func Expression(condition string) string {
+	if strings.Contains(condition, "{{") {
+		return condition
+	}
+	return "{{ " + condition + " }}"
+}
+
+// Truthy interprets the rendered output of a template expression as a boolean.
+func Truthy(rendered string) bool {
+	switch strings.ToLower(strings.TrimSpace(rendered)) {
+	case "", "false", "0", "no", "<no value>":
+		return false
+	default:
+		return true
+	}
+}
+
+// EvalCondition renders a condition against data and reports whether it holds.
+func EvalCondition(condition string, data map[string]any) (bool, error) {
+	rendered, err := RenderString(Expression(condition), data)
+	if err != nil {
+		return false, err
+	}
+	return Truthy(rendered), nil
+}
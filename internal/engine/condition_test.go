@@ -0,0 +1,25 @@
+package engine
+
+import "testing"
+
+func TestEvalCondition(t *testing.T) {
+	data := map[string]any{"use_docker": true, "env": "dev", "replicas": 0}
+	cases := map[string]bool{
+		".use_docker":                      true,
+		"{{ .use_docker }}":                true,
+		`eq .env "prod"`:                   false,
+		".replicas":                        false,
+		".missing":                         false,
+		`{{ if .use_docker }}yes{{ end }}`: true,
+	}
+
+	for condition, want := range cases {
+		got, err := EvalCondition(condition, data)
+		if err != nil {
+			t.Fatalf("EvalCondition(%q) failed: %v", condition, err)
+		}
+		if got != want {
+			t.Errorf("EvalCondition(%q) = %v, want %v", condition, got, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"sort"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+// Delims are the action delimiters of a template. Empty delimiters mean the
+// default "{{" and "}}".
+type Delims struct {
+	Left  string
+	Right string
+}
+
+func (d Delims) orDefault() (string, string) {
+	left, right := d.Left, d.Right
+	if left == "" {
+		left = "{{"
+	}
+	if right == "" {
+		right = "}}"
+	}
+	return left, right
+}
+
+// delimsFor returns the delimiters of a path relative to the template
+// directory: those of the first file_delimiters pattern matching it, in
+// sorted order, or else the template-wide delimiters.
+func delimsFor(relPath string, isDir bool, opts *config.TemplateOptions) Delims {
+	if opts == nil {
+		return Delims{}
+	}
+
+	for _, pattern := range sortedKeys(opts.FileDelimiters) {
+		if matchPath(pattern, relPath, isDir) {
+			return toDelims(opts.FileDelimiters[pattern])
+		}
+	}
+	return templateDelims(opts)
+}
+
+// templateDelims returns the template-wide delimiters.
+func templateDelims(opts *config.TemplateOptions) Delims {
+	if opts == nil {
+		return Delims{}
+	}
+	return toDelims(opts.Delimiters)
+}
+
+func toDelims(pair []string) Delims {
+	if len(pair) != 2 {
+		return Delims{}
+	}
+	return Delims{Left: pair[0], Right: pair[1]}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func TestProcessTemplateDir_Delimiters(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "{{ .slug }}")
+	dst := t.TempDir()
+	writeTestFile(t, filepath.Join(src, ".github", "workflows", "[[ .slug ]].yml"), "name: [[ .name ]]\nrun: echo ${{ github.sha }}\n")
+	writeTestFile(t, filepath.Join(src, "README.md"), "<< template \"header\" . >>\n")
+	writeTestFile(t, filepath.Join(src, "raw.txt"), "<< .slug >> <</* no_render:start */>><< .raw >><</* no_render:end */>>\n")
+	writeTestFile(t, filepath.Join(root, PartialsDirName, "header.md"), "# << .name >>")
+
+	opts := &config.TemplateOptions{
+		Delimiters:     []string{"<<", ">>"},
+		FileDelimiters: map[string][]string{".github/workflows/*": {"[[", "]]"}},
+	}
+	inputs := map[string]any{"name": "Shop", "slug": "shop"}
+	if err := ProcessTemplateDir(src, dst, inputs, opts); err != nil {
+		t.Fatalf("ProcessTemplateDir failed: %v", err)
+	}
+
+	for name, want := range map[string]string{
+		filepath.Join(".github", "workflows", "shop.yml"): "name: Shop\nrun: echo ${{ github.sha }}\n",
+		"README.md": "# Shop\n",
+		"raw.txt":   "shop << .raw >>\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", name, string(data), want)
+		}
+	}
+}
+
+func TestDelimsFor(t *testing.T) {
+	opts := &config.TemplateOptions{
+		Delimiters:     []string{"<<", ">>"},
+		FileDelimiters: map[string][]string{"charts/*": {"[[", "]]"}},
+	}
+	cases := map[string]Delims{
+		"charts/values.yaml": {Left: "[[", Right: "]]"},
+		"README.md":          {Left: "<<", Right: ">>"},
+	}
+	for relPath, want := range cases {
+		if got := delimsFor(relPath, false, opts); got != want {
+			t.Errorf("delimsFor(%q) = %+v, want %+v", relPath, got, want)
+		}
+	}
+	if got := delimsFor("README.md", false, nil); got != (Delims{}) {
+		t.Errorf("delimsFor without options = %+v", got)
+	}
+}
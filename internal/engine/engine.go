@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+// Engine renders the names and contents of the files of a template. Errors
+// it returns are *RenderErrors located in the file being rendered, or plain
+// errors that apply to the whole file.
+//
+// The engine only renders files: include_if conditions and foreach
+// expressions in the template config are Go template expressions whatever
+// the engine.
+type Engine interface {
+	// Parse parses the content of file, a path relative to the template directory.
+	Parse(file, content string) (Template, error)
+	// RenderPath renders name, a segment of the name of file or the target of
+	// the symlink file. isDir reports whether file is a directory.
+	RenderPath(file, name string, isDir bool, data map[string]any) (string, error)
+}
+
+// Template is the parsed content of a template file.
+type Template interface {
+	// Render renders the content with the inputs.
+	Render(data map[string]any) (string, error)
+}
+
+// Factory creates the engine for the template files under sourceDir.
+type Factory func(sourceDir string, opts *config.TemplateOptions) (Engine, error)
+
+// DefaultEngine is the engine of templates that do not select one.
+const DefaultEngine = "gotemplate"
+
+var engines = map[string]Factory{
+	DefaultEngine: newGoTemplateEngine,
+	"envsubst":    newEnvsubstEngine,
+}
+
+// Register makes an engine available to templates as `engine: <name>`.
+func Register(name string, factory Factory) {
+	engines[name] = factory
+}
+
+// New returns the engine selected by the engine option, or Go templates
+// when none is selected.
+func New(sourceDir string, opts *config.TemplateOptions) (Engine, error) {
+	name := DefaultEngine
+	if opts != nil && opts.Engine != "" {
+		name = opts.Engine
+	}
+
+	factory, ok := engines[name]
+	if !ok {
+		names := make([]string, 0, len(engines))
+		for known := range engines {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown engine %q: expected one of %s", name, strings.Join(names, ", "))
+	}
+	return factory(sourceDir, opts)
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func TestNew_SelectsEngine(t *testing.T) {
+	src := t.TempDir()
+	cases := map[string]string{
+		"":           "*engine.goTemplateEngine",
+		"gotemplate": "*engine.goTemplateEngine",
+		"envsubst":   "*engine.envsubstEngine",
+	}
+	for name, want := range cases {
+		eng, err := New(src, &config.TemplateOptions{Engine: name})
+		if err != nil {
+			t.Fatalf("New(%q) failed: %v", name, err)
+		}
+		if got := fmt.Sprintf("%T", eng); got != want {
+			t.Errorf("New(%q) = %s, want %s", name, got, want)
+		}
+	}
+
+	if _, err := New(src, &config.TemplateOptions{Engine: "jinja"}); err == nil || !strings.Contains(err.Error(), "envsubst, gotemplate") {
+		t.Errorf("expected an error listing the engines, got %v", err)
+	}
+}
+
+type upperEngine struct{}
+
+func (upperEngine) Parse(file, content string) (Template, error) { return upperTemplate(content), nil }
+func (upperEngine) RenderPath(file, name string, isDir bool, data map[string]any) (string, error) {
+	return strings.ToUpper(name), nil
+}
+
+type upperTemplate string
+
+func (t upperTemplate) Render(data map[string]any) (string, error) {
+	return strings.ToUpper(string(t)), nil
+}
+
+func TestRegister(t *testing.T) {
+	Register("upper", func(string, *config.TemplateOptions) (Engine, error) { return upperEngine{}, nil })
+	defer delete(engines, "upper")
+
+	src := t.TempDir()
+	dst := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "readme.md"), "hello")
+	if err := ProcessTemplateDir(src, dst, nil, &config.TemplateOptions{Engine: "upper"}); err != nil {
+		t.Fatalf("ProcessTemplateDir failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dst, "README.MD"))
+	if err != nil {
+		t.Fatalf("output file not found: %v", err)
+	}
+	if string(data) != "HELLO" {
+		t.Errorf("README.MD = %q, want %q", string(data), "HELLO")
+	}
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+// envsubstRef matches what the envsubst engine substitutes: ${name} and
+// ${name:-default}, or the escape $${, which renders as ${.
+var envsubstRef = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// envsubstEngine replaces ${name} with the value of the input name, for
+// templates that are mostly shell, Terraform or Helm, where {{ }} and $name
+// are common. References to names that are not inputs, and anything else
+// written as ${...}, such as Terraform's ${var.region}, are left as written;
+// in strict mode references to names that are not inputs are errors.
+type envsubstEngine struct {
+	strict bool
+}
+
+func newEnvsubstEngine(sourceDir string, opts *config.TemplateOptions) (Engine, error) {
+	return &envsubstEngine{strict: opts != nil && opts.Strict}, nil
+}
+
+func (e *envsubstEngine) Parse(file, content string) (Template, error) {
+	return &envsubstTemplate{engine: e, file: file, content: content}, nil
+}
+
+func (e *envsubstEngine) RenderPath(file, name string, isDir bool, data map[string]any) (string, error) {
+	return e.substitute(file, name, data)
+}
+
+// substitute replaces the references in text, the content or part of the
+// name of file.
+func (e *envsubstEngine) substitute(file, text string, data map[string]any) (string, error) {
+	var out strings.Builder
+	var errs []RenderError
+	last := 0
+	for _, m := range envsubstRef.FindAllStringSubmatchIndex(text, -1) {
+		out.WriteString(text[last:m[0]])
+		last = m[1]
+
+		if m[2] < 0 {
+			out.WriteString("${") // $${
+			continue
+		}
+		name := text[m[2]:m[3]]
+		value, ok := data[name]
+		if m[4] >= 0 && (!ok || config.IsEmpty(value)) {
+			// Like the shell's ${name:-default}, an empty value falls back too
+			out.WriteString(text[m[4]:m[5]])
+			continue
+		}
+		if ok {
+			out.WriteString(config.FormatValue(value))
+			continue
+		}
+		if e.strict {
+			line := strings.Count(text[:m[0]], "\n") + 1
+			column := m[0] - (strings.LastIndex(text[:m[0]], "\n") + 1) + 1
+			errs = append(errs, RenderError{File: file, Line: line, Column: column, Message: "${" + name + "} is not defined"})
+		}
+		out.WriteString(text[m[0]:m[1]])
+	}
+	out.WriteString(text[last:])
+
+	if len(errs) > 0 {
+		return "", &RenderErrors{Errors: errs}
+	}
+	return out.String(), nil
+}
+
+type envsubstTemplate struct {
+	engine  *envsubstEngine
+	file    string
+	content string
+}
+
+func (t *envsubstTemplate) Render(data map[string]any) (string, error) {
+	return t.engine.substitute(t.file, t.content, data)
+}
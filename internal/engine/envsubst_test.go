@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func TestEnvsubstEngine_Render(t *testing.T) {
+	eng := &envsubstEngine{}
+	data := map[string]any{"name": "shop", "services": []any{"api", "worker"}, "replicas": 3}
+	content := `name = "${name}"
+services = "${services}"
+replicas = ${replicas}
+region = "${region:-eu-west-1}"
+bucket = "${var.bucket}"
+home = "$HOME ${HOME}"
+literal = "$${name}"
+`
+	want := `name = "shop"
+services = "api,worker"
+replicas = 3
+region = "eu-west-1"
+bucket = "${var.bucket}"
+home = "$HOME ${HOME}"
+literal = "${name}"
+`
+	tmpl, err := eng.Parse("main.tf", content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	got, err := tmpl.Render(data)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("Render =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEnvsubstEngine_StrictReportsEveryUndefinedName(t *testing.T) {
+	eng := &envsubstEngine{strict: true}
+	tmpl, err := eng.Parse("deploy.sh", "echo ${name}\necho ${nmae} ${HOME} ${var.x}\n")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	_, err = tmpl.Render(map[string]any{"name": "shop"})
+
+	var renderErrs *RenderErrors
+	if !errors.As(err, &renderErrs) {
+		t.Fatalf("expected RenderErrors, got %v", err)
+	}
+	want := []RenderError{
+		{File: "deploy.sh", Line: 2, Column: 6, Message: "${nmae} is not defined"},
+		{File: "deploy.sh", Line: 2, Column: 14, Message: "${HOME} is not defined"},
+	}
+	if len(renderErrs.Errors) != len(want) {
+		t.Fatalf("errors = %v, want %v", renderErrs.Errors, want)
+	}
+	for i := range want {
+		if renderErrs.Errors[i] != want[i] {
+			t.Errorf("error %d = %+v, want %+v", i, renderErrs.Errors[i], want[i])
+		}
+	}
+}
+
+func TestEnvsubstEngine_FallbackForEmptyValues(t *testing.T) {
+	templateConfig := &config.TemplateConfig{Templating: config.TemplatingConfig{Inputs: config.Inputs{
+		{Name: "name"},
+		{Name: "registry", When: "{{ .publish }}"},
+		{Name: "publish", Type: config.InputTypeBool},
+	}}}
+	values := map[string]any{"name": "shop", "publish": false}
+
+	for _, strict := range []bool{false, true} {
+		templateConfig.Options = &config.TemplateOptions{Strict: strict}
+		eng := &envsubstEngine{strict: strict}
+		tmpl, err := eng.Parse("deploy.sh", "R=${registry:-docker.io}/${name:-app} T=${tag:-latest} E=${empty:-none}\n")
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		data := templateConfig.RenderData(values)
+		data["empty"] = ""
+		got, err := tmpl.Render(data)
+		if err != nil {
+			t.Fatalf("strict=%v: Render failed: %v", strict, err)
+		}
+		if want := "R=docker.io/shop T=latest E=none\n"; got != want {
+			t.Errorf("strict=%v: Render = %q, want %q", strict, got, want)
+		}
+	}
+}
+
+func TestProcessTemplateDir_EnvsubstEngine(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "charts", "${name}", "values.yaml"), "name: ${name}\nimage: \"{{ .Values.image }}\"\n")
+
+	opts := &config.TemplateOptions{Engine: "envsubst"}
+	if err := ProcessTemplateDir(src, dst, map[string]any{"name": "shop"}, opts); err != nil {
+		t.Fatalf("ProcessTemplateDir failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dst, "charts", "shop", "values.yaml"))
+	if err != nil {
+		t.Fatalf("output file not found: %v", err)
+	}
+	if want := "name: shop\nimage: \"{{ .Values.image }}\"\n"; string(data) != want {
+		t.Errorf("values.yaml = %q, want %q", string(data), want)
+	}
+}
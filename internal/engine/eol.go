@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"bytes"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+// Encoding is the convention of a text file: whether its lines end with
+// "\r\n" rather than "\n", and whether it starts with a UTF-8 byte order mark.
+type Encoding struct {
+	CRLF bool
+	BOM  bool
+}
+
+var bom = []byte("\xef\xbb\xbf")
+
+// DetectEncoding returns the encoding of content. Its lines end with "\r\n"
+// when most of them do.
+func DetectEncoding(content []byte) Encoding {
+	crlf := bytes.Count(content, []byte("\r\n"))
+	lf := bytes.Count(content, []byte("\n")) - crlf
+	return Encoding{CRLF: crlf > lf, BOM: bytes.HasPrefix(content, bom)}
+}
+
+// Normalize returns content without a byte order mark and with "\n" line
+// endings, so that files differing only in their encoding compare equal.
+func Normalize(content []byte) []byte {
+	content = bytes.TrimPrefix(content, bom)
+	return bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+}
+
+// Apply returns content with the line endings and byte order mark of e.
+func (e Encoding) Apply(content []byte) []byte {
+	content = Normalize(content)
+	if e.CRLF {
+		content = bytes.ReplaceAll(content, []byte("\n"), []byte("\r\n"))
+	}
+	if e.BOM {
+		content = append(append([]byte{}, bom...), content...)
+	}
+	return content
+}
+
+// EncodingFor returns the encoding of a file at relPath, relative to the
+// output directory, whose content has the encoding enc: enc with the line
+// endings forced by the eol option of opts.
+func EncodingFor(opts *config.TemplateOptions, relPath string, enc Encoding) Encoding {
+	if opts == nil {
+		return enc
+	}
+	return eolRules(opts.EOL).encoding(relPath, enc)
+}
+
+// eolRules are the eol option: the line endings forced on the generated
+// files matching each pattern.
+type eolRules map[string]string
+
+// encoding returns the encoding of a file generated at relPath, relative to
+// the output directory, from a template file with the encoding enc: enc with
+// the line endings of the first eol pattern matching relPath, in sorted
+// order.
+func (r eolRules) encoding(relPath string, enc Encoding) Encoding {
+	for _, pattern := range sortedKeys(r) {
+		if matchPath(pattern, relPath, false) {
+			enc.CRLF = r[pattern] == "crlf"
+			break
+		}
+	}
+	return enc
+}
@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		content string
+		want    Encoding
+	}{
+		{"a\nb\n", Encoding{}},
+		{"a\r\nb\r\n", Encoding{CRLF: true}},
+		{"a\r\nb\r\nc\n", Encoding{CRLF: true}},
+		{"a\r\nb\nc\n", Encoding{}},
+		{"\xef\xbb\xbfa\r\n", Encoding{CRLF: true, BOM: true}},
+		{"\xef\xbb\xbfa", Encoding{BOM: true}},
+		{"", Encoding{}},
+	}
+	for _, tt := range tests {
+		if got := DetectEncoding([]byte(tt.content)); got != tt.want {
+			t.Errorf("DetectEncoding(%q) = %+v, want %+v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestEncoding_Apply(t *testing.T) {
+	tests := []struct {
+		encoding Encoding
+		content  string
+		want     string
+	}{
+		{Encoding{}, "\xef\xbb\xbfa\r\nb\n", "a\nb\n"},
+		{Encoding{CRLF: true}, "a\nb\r\n", "a\r\nb\r\n"},
+		{Encoding{BOM: true}, "a\n", "\xef\xbb\xbfa\n"},
+		{Encoding{CRLF: true, BOM: true}, "\xef\xbb\xbfa\n", "\xef\xbb\xbfa\r\n"},
+		{Encoding{}, "a\rb\n", "a\rb\n"},
+	}
+	for _, tt := range tests {
+		if got := string(tt.encoding.Apply([]byte(tt.content))); got != tt.want {
+			t.Errorf("%+v.Apply(%q) = %q, want %q", tt.encoding, tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestProcessTemplateDir_PreservesEncoding(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "main.go"), "\xef\xbb\xbfpackage {{ .name }}\r\n\r\n\r\nfunc main( ) {\r\n}\r\n")
+	writeTestFile(t, filepath.Join(src, "notes.txt"), "{{ .lines }}\r\n")
+	writeTestFile(t, filepath.Join(src, "run.bat"), "@echo {{ .name }}\n")
+	writeTestFile(t, filepath.Join(src, "scripts", "run.sh"), "echo {{ .name }}\r\n")
+
+	opts := &config.TemplateOptions{
+		Formatters: map[string][]string{"*.go": {"gofmt"}},
+		EOL:        map[string]string{"*.bat": "crlf", "scripts/": "lf"},
+	}
+	inputs := map[string]any{"name": "shop", "lines": "one\ntwo"}
+	if err := ProcessTemplateDir(src, dst, inputs, opts); err != nil {
+		t.Fatalf("ProcessTemplateDir failed: %v", err)
+	}
+
+	for path, want := range map[string]string{
+		"main.go":                          "\xef\xbb\xbfpackage shop\r\n\r\nfunc main() {\r\n}\r\n",
+		"notes.txt":                        "one\r\ntwo\r\n",
+		"run.bat":                          "@echo shop\r\n",
+		filepath.Join("scripts", "run.sh"): "echo shop\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(dst, path))
+		if err != nil {
+			t.Fatalf("output file not found: %v", err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", path, data, want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// templateErrorPattern splits the errors of text/template, e.g.
+// `template: render:3: unexpected "}" in operand` or `template: render:2:8:
+// executing "render" at <upper 1>: error calling upper: ...`, into the
+// template name, line, optional column and message.
+var templateErrorPattern = regexp.MustCompile(`^template: (.+?):(\d+)(?::(\d+))?: (?:executing "[^"]*" at )?((?s).*)$`)
+
+// RenderError is a problem rendering one file of a template.
+type RenderError struct {
+	File    string // path relative to the template directory
+	Line    int    // 0 when unknown
+	Column  int    // 0 when unknown
+	Message string
+}
+
+func (e RenderError) String() string {
+	location := e.File
+	if e.Line > 0 {
+		location += ":" + strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			location += ":" + strconv.Itoa(e.Column)
+		}
+	}
+	return location + ": " + e.Message
+}
+
+// RenderErrors lists every problem found rendering a template, in the order
+// of the files.
+type RenderErrors struct {
+	Errors []RenderError
+}
+
+func (e *RenderErrors) Error() string {
+	lines := make([]string, 0, len(e.Errors)+1)
+	if len(e.Errors) == 1 {
+		lines = append(lines, "1 error rendering the template:")
+	} else {
+		lines = append(lines, fmt.Sprintf("%d errors rendering the template:", len(e.Errors)))
+	}
+	for _, renderErr := range e.Errors {
+		lines = append(lines, "  "+renderErr.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newRenderError locates an error of text/template. The File of the result
+// is the name of the template the error is in, or file when the error does
+// not name one.
+func newRenderError(file string, err error) RenderError {
+	m := templateErrorPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return RenderError{File: file, Message: err.Error()}
+	}
+	line, _ := strconv.Atoi(m[2])
+	column, _ := strconv.Atoi(m[3])
+	if column > 0 {
+		column++ // text/template counts columns from 0
+	}
+	return RenderError{File: m[1], Line: line, Column: column, Message: m[4]}
+}
+
+// renderErrors collects the errors found rendering a template, so that one
+// pass over the template reports all of them.
+type renderErrors []RenderError
+
+// add records an error found rendering file. offset is added to the column
+// of errors on the first line of file, for names rendered segment by segment.
+func (l *renderErrors) add(file string, offset int, err error) {
+	var located *RenderErrors
+	if !errors.As(err, &located) {
+		*l = append(*l, RenderError{File: file, Message: err.Error()})
+		return
+	}
+	for _, renderErr := range located.Errors {
+		if renderErr.File == file && renderErr.Line == 1 && renderErr.Column > 0 {
+			renderErr.Column += offset
+		}
+		*l = append(*l, renderErr)
+	}
+}
+
+// err returns a *RenderErrors when errors were found.
+func (l renderErrors) err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return &RenderErrors{Errors: l}
+}
@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewRenderError(t *testing.T) {
+	cases := []struct {
+		err  string
+		want RenderError
+	}{
+		{
+			`template: render:3: unexpected "}" in operand`,
+			RenderError{File: "render", Line: 3, Message: `unexpected "}" in operand`},
+		},
+		{
+			`template: ci/job:2:7: executing "ci/job" at <upper 1>: wrong type for value; expected string; got int`,
+			RenderError{File: "ci/job", Line: 2, Column: 8, Message: "<upper 1>: wrong type for value; expected string; got int"},
+		},
+		{
+			"read failed",
+			RenderError{File: "README.md", Message: "read failed"},
+		},
+	}
+	for _, tc := range cases {
+		if got := newRenderError("README.md", errors.New(tc.err)); got != tc.want {
+			t.Errorf("newRenderError(%q) = %+v, want %+v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestRenderErrors_Error(t *testing.T) {
+	err := &RenderErrors{Errors: []RenderError{
+		{File: "README.md", Line: 3, Column: 4, Message: ".slgu is not defined"},
+		{File: "main.go", Line: 1, Message: "unclosed action"},
+		{File: "charts", Message: `include_if "charts": boom`},
+	}}
+	want := strings.Join([]string{
+		"3 errors rendering the template:",
+		"  README.md:3:4: .slgu is not defined",
+		"  main.go:1: unclosed action",
+		`  charts: include_if "charts": boom`,
+	}, "\n")
+	if err.Error() != want {
+		t.Errorf("Error() =\n%s\nwant\n%s", err.Error(), want)
+	}
+}
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+// formatter rewrites the content of a generated file.
+type formatter func(content []byte) ([]byte, error)
+
+// formatters are the formatters the formatters option can name.
+var formatters = map[string]formatter{
+	"gofmt":                    format.Source,
+	"json":                     formatJSON,
+	"trim_trailing_whitespace": trimTrailingWhitespace,
+	"final_newline":            finalNewline,
+}
+
+// formatJSON indents JSON with two spaces, dropping the blank lines and
+// stray whitespace left by conditionals.
+func formatJSON(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimSpace(content), "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+var trailingWhitespace = regexp.MustCompile(`(?m)[ \t]+(\r?)$`)
+
+func trimTrailingWhitespace(content []byte) ([]byte, error) {
+	return trailingWhitespace.ReplaceAll(content, []byte("$1")), nil
+}
+
+// finalNewline ends non-empty content with exactly one newline.
+func finalNewline(content []byte) ([]byte, error) {
+	trimmed := bytes.TrimRight(content, "\r\n")
+	if len(trimmed) == 0 {
+		return trimmed, nil
+	}
+	newline := "\n"
+	if bytes.Contains(content, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+	return append(trimmed, newline...), nil
+}
+
+// formatRules are the formatters option: the formatters to run on the
+// generated files matching each pattern.
+type formatRules struct {
+	patterns   []string // sorted, so that formatters run in the same order every time
+	formatters map[string][]string
+}
+
+// newFormatRules reads the formatters option, rejecting unknown formatters.
+func newFormatRules(opts *config.TemplateOptions) (formatRules, error) {
+	if opts == nil {
+		return formatRules{}, nil
+	}
+	rules := formatRules{formatters: opts.Formatters}
+	for pattern, names := range opts.Formatters {
+		for _, name := range names {
+			if _, ok := formatters[name]; !ok {
+				known := make([]string, 0, len(formatters))
+				for formatterName := range formatters {
+					known = append(known, formatterName)
+				}
+				sort.Strings(known)
+				return formatRules{}, fmt.Errorf("formatters %q: unknown formatter %q: expected one of %s", pattern, name, strings.Join(known, ", "))
+			}
+		}
+		rules.patterns = append(rules.patterns, pattern)
+	}
+	sort.Strings(rules.patterns)
+	return rules, nil
+}
+
+// format runs the formatters of every pattern matching relPath, a path
+// relative to the output directory, on content.
+func (r formatRules) format(relPath string, content []byte) ([]byte, error) {
+	for _, pattern := range r.patterns {
+		if !matchPath(pattern, relPath, false) {
+			continue
+		}
+		for _, name := range r.formatters[pattern] {
+			formatted, err := formatters[name](content)
+			if err != nil {
+				return nil, fmt.Errorf("formatter %s: %w", name, err)
+			}
+			content = formatted
+		}
+	}
+	return content, nil
+}
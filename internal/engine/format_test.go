@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func TestFormatters(t *testing.T) {
+	tests := []struct {
+		name      string
+		formatter string
+		content   string
+		want      string
+	}{
+		{"gofmt", "gofmt", "package main\n\n\n\nfunc main() {\nx:=1\n_ = x\n}\n", "package main\n\nfunc main() {\n\tx := 1\n\t_ = x\n}\n"},
+		{"json", "json", "{\n\n  \"a\": 1,\n\n\"b\": [1,2]   }", "{\n  \"a\": 1,\n  \"b\": [\n    1,\n    2\n  ]\n}\n"},
+		{"trim trailing whitespace", "trim_trailing_whitespace", "a  \nb\t\r\nc ", "a\nb\r\nc"},
+		{"add final newline", "final_newline", "a\nb", "a\nb\n"},
+		{"drop extra final newlines", "final_newline", "a\n\n\n", "a\n"},
+		{"keep CRLF final newline", "final_newline", "a\r\nb", "a\r\nb\r\n"},
+		{"leave empty file empty", "final_newline", "\n\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatters[tt.formatter]([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("%s failed: %v", tt.formatter, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s = %q, want %q", tt.formatter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFormatRules_UnknownFormatter(t *testing.T) {
+	opts := &config.TemplateOptions{Formatters: map[string][]string{"*.py": {"black"}}}
+	_, err := newFormatRules(opts)
+	if err == nil || !strings.Contains(err.Error(), `unknown formatter "black"`) {
+		t.Errorf("expected unknown formatter error, got %v", err)
+	}
+}
+
+func TestFormatRules_RunsMatchingFormattersInOrder(t *testing.T) {
+	rules, err := newFormatRules(&config.TemplateOptions{Formatters: map[string][]string{
+		"*":      {"trim_trailing_whitespace", "final_newline"},
+		"*.json": {"json"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := rules.format("config/app.json", []byte("{\"a\": 1}  "))
+	if err != nil {
+		t.Fatalf("format failed: %v", err)
+	}
+	if want := "{\n  \"a\": 1\n}\n"; string(got) != want {
+		t.Errorf("app.json = %q, want %q", got, want)
+	}
+
+	got, err = rules.format("notes.txt", []byte("a  \n\n"))
+	if err != nil {
+		t.Fatalf("format failed: %v", err)
+	}
+	if want := "a\n"; string(got) != want {
+		t.Errorf("notes.txt = %q, want %q", got, want)
+	}
+}
+
+func TestProcessTemplateDir_Formatters(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "{{ .name }}.go"), "package {{ .name }}\n{{ if .debug }}\nimport \"fmt\"\n{{ end }}\nfunc Hello( ) {\n}")
+	writeTestFile(t, filepath.Join(src, "broken.go"), "package {")
+
+	opts := &config.TemplateOptions{Formatters: map[string][]string{"*.go": {"gofmt"}}}
+	err := ProcessTemplateDir(src, dst, map[string]any{"name": "shop", "debug": false}, opts)
+
+	var renderErrs *RenderErrors
+	if !errors.As(err, &renderErrs) || len(renderErrs.Errors) != 1 {
+		t.Fatalf("expected one RenderError, got %v", err)
+	}
+	if got := renderErrs.Errors[0]; got.File != "broken.go" || !strings.HasPrefix(got.Message, "formatter gofmt: ") {
+		t.Errorf("error = %+v, want a gofmt error in broken.go", got)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "broken.go")); !os.IsNotExist(err) {
+		t.Error("expected broken.go to be left out")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dst, "shop.go"))
+	if err != nil {
+		t.Fatalf("output file not found: %v", err)
+	}
+	if want := "package shop\n\nfunc Hello() {\n}\n"; string(data) != want {
+		t.Errorf("shop.go = %q, want %q", data, want)
+	}
+}
@@ -0,0 +1,200 @@
+package engine
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// now is the clock used by the date functions. Tests replace it to get
+// deterministic output.
+var now = time.Now
+
+// funcMap returns the functions available to every template rendered by the
+// engine, in file contents and in file and directory names alike.
+func funcMap() template.FuncMap {
+	return template.FuncMap{
+		// Case conversion
+		"lower":  strings.ToLower,
+		"upper":  strings.ToUpper,
+		"kebab":  func(s string) string { return strings.Join(words(s), "-") },
+		"snake":  func(s string) string { return strings.Join(words(s), "_") },
+		"camel":  camel,
+		"pascal": pascal,
+		"title":  title,
+
+		// Strings
+		"trim":      strings.TrimSpace,
+		"replace":   func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"pluralize": pluralize,
+		"indent":    indent,
+		"nindent":   func(spaces int, s string) string { return "\n" + indent(spaces, s) },
+		"quote":     func(v any) string { return strconv.Quote(fmt.Sprint(v)) },
+
+		// Data
+		"toYaml":  toYaml,
+		"toJson":  toJson,
+		"default": func(fallback, value any) any { return ternary(fallback, value, empty(value)) },
+		"ternary": func(whenTrue, whenFalse any, cond bool) any { return ternary(whenTrue, whenFalse, cond) },
+
+		// Values
+		"uuid":   newUUID,
+		"sha256": func(s string) string { sum := sha256.Sum256([]byte(s)); return hex.EncodeToString(sum[:]) },
+		"now":    func() time.Time { return now() },
+		"date":   func(layout string) string { return now().Format(layout) },
+	}
+}
+
+// words splits s into lower-cased words on spaces, punctuation and
+// lower-to-upper case transitions, e.g. "My HTTPServer_v2" → [my http server v2].
+func words(s string) []string {
+	var result []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			result = append(result, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+
+	return result
+}
+
+// capitalize upper-cases the first letter of a word.
+func capitalize(word string) string {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return word
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// camel joins the words of s as camelCase, e.g. "my project" → "myProject".
+func camel(s string) string {
+	parts := words(s)
+	for i := 1; i < len(parts); i++ {
+		parts[i] = capitalize(parts[i])
+	}
+	return strings.Join(parts, "")
+}
+
+// pascal joins the words of s as PascalCase, e.g. "my project" → "MyProject".
+func pascal(s string) string {
+	parts := words(s)
+	for i := range parts {
+		parts[i] = capitalize(parts[i])
+	}
+	return strings.Join(parts, "")
+}
+
+// title joins the capitalized words of s with spaces, e.g. "my-project" → "My Project".
+func title(s string) string {
+	parts := words(s)
+	for i := range parts {
+		parts[i] = capitalize(parts[i])
+	}
+	return strings.Join(parts, " ")
+}
+
+// pluralize returns the English plural of a singular noun using the regular
+// rules: "service" → "services", "box" → "boxes", "policy" → "policies".
+func pluralize(word string) string {
+	lower := strings.ToLower(word)
+	switch {
+	case word == "":
+		return word
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return word + "es"
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return word[:len(word)-1] + "ies"
+	default:
+		return word + "s"
+	}
+}
+
+// indent prefixes every line of s with the given number of spaces.
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+// toYaml encodes v as YAML without the trailing newline.
+func toYaml(v any) (string, error) {
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// toJson encodes v as compact JSON.
+func toJson(v any) (string, error) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// empty reports whether v is nil or the zero value of its type, or an empty
+// string, slice or map.
+func empty(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	default:
+		return rv.IsZero()
+	}
+}
+
+func ternary(whenTrue, whenFalse any, cond bool) any {
+	if cond {
+		return whenTrue
+	}
+	return whenFalse
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
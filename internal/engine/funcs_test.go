@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestCaseFuncs(t *testing.T) {
+	cases := []struct {
+		tmpl string
+		want string
+	}{
+		{`{{ "My HTTPServer v2" | kebab }}`, "my-http-server-v2"},
+		{`{{ "myProject Name" | snake }}`, "my_project_name"},
+		{`{{ "Mixed Case" | lower }}`, "mixed case"},
+		{`{{ "Mixed Case" | upper }}`, "MIXED CASE"},
+	}
+
+	for _, tc := range cases {
+		got, err := RenderString(tc.tmpl, nil)
+		if err != nil {
+			t.Fatalf("RenderString(%q) failed: %v", tc.tmpl, err)
+		}
+		if got != tc.want {
+			t.Errorf("RenderString(%q) = %q, want %q", tc.tmpl, got, tc.want)
+		}
+	}
+}
+
+func TestFuncs(t *testing.T) {
+	data := map[string]any{
+		"name":     "billing api",
+		"services": []any{"api", "worker"},
+		"config":   map[string]any{"port": 8080},
+		"empty":    "",
+		"enabled":  true,
+	}
+	cases := []struct {
+		tmpl string
+		want string
+	}{
+		{`{{ .name | camel }}`, "billingApi"},
+		{`{{ .name | pascal }}`, "BillingApi"},
+		{`{{ "my-project_name" | title }}`, "My Project Name"},
+		{`{{ "  padded  " | trim }}`, "padded"},
+		{`{{ .name | replace " " "." }}`, "billing.api"},
+		{`{{ "service" | pluralize }} {{ "box" | pluralize }} {{ "policy" | pluralize }} {{ "day" | pluralize }}`, "services boxes policies days"},
+		{`{{ "a\nb" | indent 2 }}`, "  a\n  b"},
+		{`{{ "a" | nindent 4 }}`, "\n    a"},
+		{`{{ .name | quote }}`, `"billing api"`},
+		{`{{ .services | toYaml }}`, "- api\n- worker"},
+		{`{{ .config | toJson }}`, `{"port":8080}`},
+		{`{{ .empty | default "fallback" }} {{ .name | default "fallback" }}`, "fallback billing api"},
+		{`{{ .missing | default 3 }}`, "3"},
+		{`{{ ternary "on" "off" .enabled }}`, "on"},
+		{`{{ "abc" | sha256 }}`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+
+	for _, tc := range cases {
+		got, err := RenderString(tc.tmpl, data)
+		if err != nil {
+			t.Fatalf("RenderString(%q) failed: %v", tc.tmpl, err)
+		}
+		if got != tc.want {
+			t.Errorf("RenderString(%q) = %q, want %q", tc.tmpl, got, tc.want)
+		}
+	}
+}
+
+func TestDateFuncsUseTheClock(t *testing.T) {
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC) }
+
+	got, err := RenderString(`{{ date "2006-01-02" }} {{ now.Year }}`, nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if got != "2024-03-09 2024" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestUUID(t *testing.T) {
+	got, err := RenderString(`{{ uuid }}`, nil)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(got) {
+		t.Errorf("uuid = %q is not a version 4 UUID", got)
+	}
+}
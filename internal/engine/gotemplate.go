@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"bytes"
+	"path/filepath"
+	"text/template"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+// goTemplateEngine renders templates with text/template, the functions of
+// funcMap and the partials of the template's _partials directories. In
+// strict mode references to undefined inputs are errors.
+type goTemplateEngine struct {
+	opts     *config.TemplateOptions
+	partials *template.Template
+	strict   bool
+}
+
+// newGoTemplateEngine loads the partials from the _partials directory of the
+// template root, the parent of sourceDir, and of sourceDir itself.
+func newGoTemplateEngine(sourceDir string, opts *config.TemplateOptions) (Engine, error) {
+	partials, err := LoadPartials(templateDelims(opts), filepath.Dir(sourceDir), sourceDir)
+	if err != nil {
+		return nil, err
+	}
+	return &goTemplateEngine{opts: opts, partials: partials, strict: opts != nil && opts.Strict}, nil
+}
+
+func (e *goTemplateEngine) Parse(file, content string) (Template, error) {
+	delims := delimsFor(file, false, e.opts)
+	processed, rawBlocks, err := PreprocessRawBlocksDelims(content, delims)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl := &goTemplate{engine: e, file: file, processed: processed, rawBlocks: rawBlocks}
+	tmpl.tmpl, err = e.parse(file, processed, delims)
+	if err != nil {
+		return nil, tmpl.locate(newRenderError(file, err))
+	}
+	return tmpl, nil
+}
+
+func (e *goTemplateEngine) RenderPath(file, name string, isDir bool, data map[string]any) (string, error) {
+	tmpl, err := e.parse(file, name, delimsFor(file, isDir, e.opts))
+	if err != nil {
+		return "", e.locate(file, newRenderError(file, err))
+	}
+	out, errs := e.execute(tmpl, data)
+	if len(errs) > 0 {
+		for i := range errs {
+			errs[i] = e.locateIn(file, errs[i])
+		}
+		return "", &RenderErrors{Errors: errs}
+	}
+	return out, nil
+}
+
+// parse parses text as a template named after file, so that errors name it,
+// with the partials available to {{ template }} actions.
+func (e *goTemplateEngine) parse(file, text string, delims Delims) (*template.Template, error) {
+	var tmpl *template.Template
+	if e.partials != nil {
+		set, err := e.partials.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if e.strict {
+			for _, partial := range set.Templates() {
+				partial.Option("missingkey=error")
+			}
+		}
+		tmpl = set.New(file)
+	} else {
+		tmpl = template.New(file).Funcs(funcMap())
+	}
+	if e.strict {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+	return tmpl.Delims(delims.Left, delims.Right).Parse(text)
+}
+
+// execute executes tmpl. In strict mode it returns every undefined reference.
+func (e *goTemplateEngine) execute(tmpl *template.Template, data map[string]any) (string, []RenderError) {
+	if e.strict {
+		return executeStrict(tmpl, data)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", []RenderError{newRenderError(tmpl.Name(), err)}
+	}
+	return buf.String(), nil
+}
+
+func (e *goTemplateEngine) locate(file string, renderErr RenderError) error {
+	return &RenderErrors{Errors: []RenderError{e.locateIn(file, renderErr)}}
+}
+
+// locateIn locates an error of the template rendered for file: errors in
+// the file itself keep their location, and errors in a partial are located
+// in the _partials directory.
+func (e *goTemplateEngine) locateIn(file string, renderErr RenderError) RenderError {
+	if renderErr.File != file {
+		renderErr.File = filepath.Join(PartialsDirName, renderErr.File)
+	}
+	return renderErr
+}
+
+// goTemplate is the content of a file, with its no_render blocks replaced
+// by placeholders.
+type goTemplate struct {
+	engine    *goTemplateEngine
+	file      string
+	tmpl      *template.Template
+	processed string
+	rawBlocks map[string]string
+}
+
+func (t *goTemplate) Render(data map[string]any) (string, error) {
+	out, errs := t.engine.execute(t.tmpl, data)
+	if len(errs) > 0 {
+		renderErrs := &RenderErrors{}
+		for _, renderErr := range errs {
+			renderErrs.Errors = append(renderErrs.Errors, t.locate(renderErr).Errors...)
+		}
+		return "", renderErrs
+	}
+	return PostprocessRawBlocks(out, t.rawBlocks), nil
+}
+
+// locate locates an error in the file, on its line before the no_render
+// blocks were replaced.
+func (t *goTemplate) locate(renderErr RenderError) *RenderErrors {
+	renderErr = t.engine.locateIn(t.file, renderErr)
+	if renderErr.File == t.file && renderErr.Line > 0 {
+		renderErr.Line = originalLine(t.processed, t.rawBlocks, renderErr.Line, renderErr.Column)
+	}
+	return &RenderErrors{Errors: []RenderError{renderErr}}
+}
@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Workers returns the number of files rendered at once for the --jobs flag:
+// jobs itself, or the number of CPUs when jobs is 0 or less.
+func Workers(jobs int) int {
+	if jobs <= 0 {
+		return runtime.NumCPU()
+	}
+	return jobs
+}
+
+// forEachJob calls fn with 0 to n-1 on at most Workers(jobs) goroutines. It
+// returns the error of the lowest index, so that the result does not depend
+// on which goroutine ran first.
+func forEachJob(n, jobs int, fn func(i int) error) error {
+	errs := make([]error, n)
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(Workers(jobs), n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
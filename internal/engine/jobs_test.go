@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func TestWorkers(t *testing.T) {
+	if got := Workers(3); got != 3 {
+		t.Errorf("Workers(3) = %d, want 3", got)
+	}
+	if got := Workers(0); got < 1 {
+		t.Errorf("Workers(0) = %d, want at least 1", got)
+	}
+}
+
+func TestForEachJob_ReturnsErrorOfLowestIndex(t *testing.T) {
+	var calls atomic.Int32
+	err := forEachJob(50, 4, func(i int) error {
+		calls.Add(1)
+		if i == 7 || i == 30 {
+			return fmt.Errorf("job %d", i)
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "job 7" {
+		t.Errorf("forEachJob = %v, want job 7", err)
+	}
+	if calls.Load() != 50 {
+		t.Errorf("expected every job to run, ran %d", calls.Load())
+	}
+}
+
+func TestProcessTemplateDirJobs_SameOutputAndErrorsForAnyJobs(t *testing.T) {
+	src := t.TempDir()
+	for i := range 40 {
+		writeTestFile(t, filepath.Join(src, fmt.Sprintf("dir%d", i%4), fmt.Sprintf("file%02d.txt", i)), fmt.Sprintf("{{ .name }} %d\n", i))
+	}
+	writeTestFile(t, filepath.Join(src, "dir1", "broken.txt"), "{{ .name ")
+	writeTestFile(t, filepath.Join(src, "dir2", "{{ .missing | upper }}.txt"), "x")
+	writeTestFile(t, filepath.Join(src, "dir3", "{{ .item }}.txt"), "{{ .item }} {{ .nmae }}\n")
+	// Both elements render to the same path; the last one wins
+	writeTestFile(t, filepath.Join(src, "same", "{{ if .item }}out{{ end }}.txt"), "{{ .item }}\n")
+
+	opts := &config.TemplateOptions{
+		Strict: true,
+		Foreach: map[string]string{
+			"dir3/{{ .item }}.txt":                ".services",
+			"same/{{ if .item }}out{{ end }}.txt": ".services",
+		},
+	}
+	inputs := map[string]any{"name": "shop", "services": []any{"api", "worker"}}
+
+	render := func(jobs int) (map[string]string, []RenderError) {
+		dst := t.TempDir()
+		err := ProcessTemplateDirJobs(src, dst, inputs, opts, jobs)
+		var renderErrs *RenderErrors
+		if !errors.As(err, &renderErrs) {
+			t.Fatalf("expected RenderErrors with %d jobs, got %v", jobs, err)
+		}
+
+		files := make(map[string]string)
+		err = filepath.Walk(dst, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			relPath, _ := filepath.Rel(dst, path)
+			files[relPath] = string(data)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return files, renderErrs.Errors
+	}
+
+	serialFiles, serialErrs := render(1)
+	if len(serialFiles) != 40+1 {
+		t.Errorf("expected 41 files, got %d", len(serialFiles))
+	}
+	if got := serialFiles[filepath.Join("same", "out.txt")]; got != "worker\n" {
+		t.Errorf("same/out.txt = %q, want the last element", got)
+	}
+	if len(serialErrs) != 4 {
+		t.Errorf("expected 4 errors, got %v", serialErrs)
+	}
+
+	for range 5 {
+		files, errs := render(8)
+		if !reflect.DeepEqual(files, serialFiles) {
+			t.Errorf("output with 8 jobs differs from the serial output")
+		}
+		if !reflect.DeepEqual(errs, serialErrs) {
+			t.Errorf("errors with 8 jobs = %v, want %v", errs, serialErrs)
+		}
+	}
+}
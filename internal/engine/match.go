@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// IgnoreFileName is the file in the template directory listing, in
+// .gitignore syntax, template files that never reach the project.
+const IgnoreFileName = ".sygkroignore"
+
+// Path options such as skip_render, exclude and the keys of include_if use
+// .gitignore syntax against paths relative to the template directory:
+//
+//   - a pattern without a slash, e.g. "*.png", matches a name at any depth
+//   - a pattern with a slash, e.g. "docs/*.md", matches from the template
+//     directory, and "**" matches any number of directories
+//   - a trailing slash, e.g. "fixtures/", only matches directories
+//   - a pattern matching a directory matches everything below it
+//
+// In a list of patterns, later patterns override earlier ones and a leading
+// "!" re-includes paths an earlier pattern matched.
+
+// pathList is an ordered list of .gitignore-style patterns.
+// The zero value matches nothing.
+type pathList struct {
+	matcher gitignore.Matcher
+}
+
+func newPathList(patterns []string) pathList {
+	parsed := make([]gitignore.Pattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		parsed = append(parsed, gitignore.ParsePattern(pattern, nil))
+	}
+	return pathList{matcher: gitignore.NewMatcher(parsed)}
+}
+
+// match reports whether the last pattern matching relPath is not negated.
+func (l pathList) match(relPath string, isDir bool) bool {
+	if l.matcher == nil {
+		return false
+	}
+	return l.matcher.Match(splitRelPath(relPath), isDir)
+}
+
+// matchPath reports whether a single .gitignore-style pattern matches relPath.
+// A negated pattern matches nothing.
+func matchPath(pattern, relPath string, isDir bool) bool {
+	return gitignore.ParsePattern(pattern, nil).Match(splitRelPath(relPath), isDir) == gitignore.Exclude
+}
+
+// matchPathExactly is matchPath for options applied once to the topmost
+// match, such as foreach: it ignores paths matched only because their parent is.
+func matchPathExactly(pattern, relPath string, isDir bool) bool {
+	return matchPath(pattern, relPath, isDir) && !matchPath(pattern, filepath.Dir(relPath), true)
+}
+
+func splitRelPath(relPath string) []string {
+	if relPath == "." || relPath == "" {
+		return nil
+	}
+	return strings.Split(filepath.ToSlash(relPath), "/")
+}
+
+// readIgnoreFile returns the patterns of the .sygkroignore file in dir,
+// skipping blank lines and comments. A missing file has no patterns.
+func readIgnoreFile(dir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(dir, IgnoreFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFileName, err)
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFileName, err)
+	}
+	return patterns, nil
+}
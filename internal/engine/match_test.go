@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPathList(t *testing.T) {
+	list := newPathList([]string{"**/*.png", "fixtures/", "docs/*.md", "!docs/index.md"})
+	cases := []struct {
+		relPath string
+		isDir   bool
+		want    bool
+	}{
+		{"logo.png", false, true},
+		{filepath.Join("assets", "img", "logo.png"), false, true},
+		{"fixtures", true, true},
+		{filepath.Join("fixtures", "input.txt"), false, true},
+		{"fixtures", false, false},
+		{filepath.Join("docs", "guide.md"), false, true},
+		{filepath.Join("docs", "index.md"), false, false},
+		{filepath.Join("src", "docs", "guide.md"), false, false},
+		{"README.md", false, false},
+	}
+	for _, tc := range cases {
+		if got := list.match(tc.relPath, tc.isDir); got != tc.want {
+			t.Errorf("match(%q, %v) = %v, want %v", tc.relPath, tc.isDir, got, tc.want)
+		}
+	}
+
+	var zero pathList
+	if zero.match("README.md", false) {
+		t.Error("zero pathList should match nothing")
+	}
+}
+
+func TestMatchPathExactly(t *testing.T) {
+	pattern := filepath.Join("services", "{{ .item }}")
+	if !matchPathExactly(pattern, pattern, true) {
+		t.Error("expected the directory itself to match")
+	}
+	if matchPathExactly(pattern, filepath.Join(pattern, "main.go"), false) {
+		t.Error("expected files below the directory not to match")
+	}
+	if !matchPath(pattern, filepath.Join(pattern, "main.go"), false) {
+		t.Error("expected matchPath to match files below the directory")
+	}
+}
+
+func TestReadIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	if patterns, err := readIgnoreFile(dir); err != nil || patterns != nil {
+		t.Fatalf("missing file: patterns = %v, err = %v", patterns, err)
+	}
+
+	content := "# test fixtures\nfixtures/\n\n*.snap\n"
+	if err := os.WriteFile(filepath.Join(dir, IgnoreFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	patterns, err := readIgnoreFile(dir)
+	if err != nil {
+		t.Fatalf("readIgnoreFile failed: %v", err)
+	}
+	if want := []string{"fixtures/", "*.snap"}; !reflect.DeepEqual(patterns, want) {
+		t.Errorf("patterns = %v, want %v", patterns, want)
+	}
+}
@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// PartialsDirName is the directory of shared snippets that every rendered
+// file can include with {{ template "name" . }}.
+const PartialsDirName = "_partials"
+
+// LoadPartials parses every file under the _partials directory of each of
+// dirs, in order, into one template set. A partial is named after its path in
+// the _partials directory without the extension, so _partials/ci/job.yaml
+// becomes "ci/job". Partials use the given delimiters. Missing directories
+// are skipped. Partials that do not parse are reported together as
+// *RenderErrors.
+func LoadPartials(delims Delims, dirs ...string) (*template.Template, error) {
+	partials := template.New(PartialsDirName).Delims(delims.Left, delims.Right).Funcs(funcMap())
+	var errs []RenderError
+
+	for _, dir := range dirs {
+		partialsDir := filepath.Join(dir, PartialsDirName)
+		if info, err := os.Stat(partialsDir); err != nil || !info.IsDir() {
+			continue
+		}
+
+		err := filepath.Walk(partialsDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+
+			relPath, err := filepath.Rel(partialsDir, path)
+			if err != nil {
+				return err
+			}
+			name := filepath.ToSlash(strings.TrimSuffix(relPath, filepath.Ext(relPath)))
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if _, err := partials.New(name).Parse(string(content)); err != nil {
+				renderErr := newRenderError(name, err)
+				renderErr.File = filepath.Join(PartialsDirName, relPath)
+				errs = append(errs, renderErr)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, &RenderErrors{Errors: errs}
+	}
+	return partials, nil
+}
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessTemplateDir_Partials(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "{{ .slug }}")
+	dst := t.TempDir()
+	writeTestFile(t, filepath.Join(root, PartialsDirName, "license-header.txt"), "// Copyright {{ .owner }}")
+	writeTestFile(t, filepath.Join(src, PartialsDirName, "ci", "job.yaml"), "job: {{ .slug }}")
+	writeTestFile(t, filepath.Join(src, "main.go"), "{{ template \"license-header\" . }}\npackage main\n")
+	writeTestFile(t, filepath.Join(src, "ci.yaml"), "{{ template \"ci/job\" . }}\n")
+
+	inputs := map[string]any{"owner": "Acme", "slug": "shop"}
+	if err := ProcessTemplateDir(src, dst, inputs, nil); err != nil {
+		t.Fatalf("ProcessTemplateDir failed: %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"main.go": "// Copyright Acme\npackage main\n",
+		"ci.yaml": "job: shop\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", name, string(data), want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, PartialsDirName)); !os.IsNotExist(err) {
+		t.Error("the _partials directory must not be copied to the output")
+	}
+}
+
+func TestLoadPartials_ParseErrorNamesTheFile(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, PartialsDirName, "broken.txt"), "{{ .name ")
+
+	_, err := LoadPartials(Delims{}, root)
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(PartialsDirName, "broken.txt")) {
+		t.Errorf("expected error naming the partial file, got %v", err)
+	}
+}
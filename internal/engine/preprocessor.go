@@ -11,12 +11,21 @@ import (
 // raw block with a unique placeholder and returns the processed content along with
 // a map of placeholders to their original content.
 func PreprocessRawBlocks(content string) (string, map[string]string, error) {
+	return PreprocessRawBlocksDelims(content, Delims{})
+}
+
+// PreprocessRawBlocksDelims is PreprocessRawBlocks for templates using the
+// given delimiters, whose markers are e.g. "[[/* no_render:start */]]".
+func PreprocessRawBlocksDelims(content string, delims Delims) (string, map[string]string, error) {
+	left, right := delims.orDefault()
+
 	// Regex explanation:
 	// (?s)                   : Enable dot-all mode so '.' matches newline.
 	// {{/\*\s*no_render:start\s*\*/}} : Matches the start marker.
 	// (.*?)                 : Lazily captures everything until the end marker.
 	// {{/\*\s*no_render:end\s*\*/}}   : Matches the end marker.
-	pattern := `(?s){{/\*\s*no_render:start\s*\*/}}(.*?){{/\*\s*no_render:end\s*\*/}}`
+	left, right = regexp.QuoteMeta(left), regexp.QuoteMeta(right)
+	pattern := `(?s)` + left + `/\*\s*no_render:start\s*\*/` + right + `(.*?)` + left + `/\*\s*no_render:end\s*\*/` + right
 	re, err := regexp.Compile(pattern)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to compile regex: %w", err)
@@ -49,3 +58,24 @@ func PostprocessRawBlocks(content string, rawBlocks map[string]string) string {
 	}
 	return content
 }
+
+// originalLine maps a position in content returned by PreprocessRawBlocks
+// back to its line in the original content, where the raw blocks before it
+// may have spanned several lines.
+func originalLine(processed string, rawBlocks map[string]string, line, column int) int {
+	offset := 0
+	for i := 1; i < line; i++ {
+		next := strings.IndexByte(processed[offset:], '\n')
+		if next < 0 {
+			return line
+		}
+		offset += next + 1
+	}
+	offset = min(offset+max(column-1, 0), len(processed))
+
+	before := processed[:offset]
+	for placeholder, rawContent := range rawBlocks {
+		line += strings.Count(before, placeholder) * strings.Count(rawContent, "\n")
+	}
+	return line
+}
@@ -102,3 +102,14 @@ func TestPostprocessRawBlocks_ExtraPlaceholder(t *testing.T) {
 		t.Errorf("postprocess mismatch: got %q, want %q", output, want)
 	}
 }
+
+func TestOriginalLine_WithoutColumn(t *testing.T) {
+	processed := "__NO_RENDER_BLOCK_0__\n{{ .name "
+	rawBlocks := map[string]string{"__NO_RENDER_BLOCK_0__": "a\nb\nc"}
+	if got := originalLine(processed, rawBlocks, 1, 0); got != 1 {
+		t.Errorf("originalLine(1, 0) = %d, want 1", got)
+	}
+	if got := originalLine(processed, rawBlocks, 2, 0); got != 4 {
+		t.Errorf("originalLine(2, 0) = %d, want 4", got)
+	}
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"text/template"
+	"text/template/parse"
+)
+
+// TemplateRefs returns the sorted, de-duplicated names of the top-level data
+// fields referenced by a template string, e.g. "{{ .name | kebab }}" → [name].
+// References inside range and with blocks, where the dot has been rebound,
+// are only counted when written as $.field.
+func TemplateRefs(tmplStr string) ([]string, error) {
+	tmpl, err := template.New("refs").Funcs(funcMap()).Parse(tmplStr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+
+	refs := make(map[string]bool)
+	if tmpl.Tree != nil {
+		collectRefs(tmpl.Tree.Root, true, refs)
+	}
+
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// collectRefs walks a parse tree recording field references. topDot reports
+// whether "." still refers to the top-level data at this point in the tree.
+func collectRefs(node parse.Node, topDot bool, refs map[string]bool) {
+	switch n := node.(type) {
+	case nil:
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectRefs(child, topDot, refs)
+		}
+	case *parse.ActionNode:
+		collectRefs(n.Pipe, topDot, refs)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectRefs(cmd, topDot, refs)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectRefs(arg, topDot, refs)
+		}
+	case *parse.FieldNode:
+		if topDot && len(n.Ident) > 0 {
+			refs[n.Ident[0]] = true
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			refs[n.Ident[1]] = true
+		}
+	case *parse.ChainNode:
+		collectRefs(n.Node, topDot, refs)
+	case *parse.IfNode:
+		collectRefs(n.Pipe, topDot, refs)
+		collectRefs(n.List, topDot, refs)
+		collectRefs(n.ElseList, topDot, refs)
+	case *parse.RangeNode:
+		collectRefs(n.Pipe, topDot, refs)
+		collectRefs(n.List, false, refs)
+		collectRefs(n.ElseList, topDot, refs)
+	case *parse.WithNode:
+		collectRefs(n.Pipe, topDot, refs)
+		collectRefs(n.List, false, refs)
+		collectRefs(n.ElseList, topDot, refs)
+	case *parse.TemplateNode:
+		collectRefs(n.Pipe, topDot, refs)
+	}
+}
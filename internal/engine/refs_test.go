@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTemplateRefs(t *testing.T) {
+	cases := []struct {
+		tmpl string
+		want []string
+	}{
+		{"{{ .name | kebab }}", []string{"name"}},
+		{"{{ .org }}/{{ .name }}-{{ .org }}", []string{"name", "org"}},
+		{"{{ if .use_docker }}{{ .registry }}{{ end }}", []string{"registry", "use_docker"}},
+		{"{{ range .services }}{{ .port }}{{ $.prefix }}{{ end }}", []string{"prefix", "services"}},
+		{"{{ with .db }}{{ .host }}{{ end }}", []string{"db"}},
+		{"plain text", []string{}},
+	}
+
+	for _, tc := range cases {
+		got, err := TemplateRefs(tc.tmpl)
+		if err != nil {
+			t.Fatalf("TemplateRefs(%q) failed: %v", tc.tmpl, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("TemplateRefs(%q) = %v, want %v", tc.tmpl, got, tc.want)
+		}
+	}
+}
+
+func TestTemplateRefs_ParseError(t *testing.T) {
+	if _, err := TemplateRefs("{{ .name "); err == nil {
+		t.Error("expected parse error")
+	}
+}
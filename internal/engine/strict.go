@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// maxUndefinedRefs bounds the re-executions of one template in strict mode.
+const maxUndefinedRefs = 100
+
+// missingKeyMessage matches the message of the error text/template reports
+// with missingkey=error, e.g. `<.a.b>: map has no entry for key "b"`.
+var missingKeyMessage = regexp.MustCompile(`^<([^>]*)>: map has no entry for key "[^"]*"$`)
+
+// executeStrict executes tmpl, which uses missingkey=error, and returns every
+// undefined reference rather than stopping at the first one: each missing
+// key is given a placeholder value and the template executed again. The File
+// of the errors is the name of the template they were found in.
+func executeStrict(tmpl *template.Template, data map[string]any) (string, []RenderError) {
+	var errs []RenderError
+	seen := make(map[RenderError]bool)
+
+	for range maxUndefinedRefs {
+		var buf bytes.Buffer
+		err := tmpl.Execute(&buf, data)
+		if err == nil {
+			return buf.String(), errs
+		}
+
+		renderErr := newRenderError(tmpl.Name(), err)
+		m := missingKeyMessage.FindStringSubmatch(renderErr.Message)
+		if m == nil {
+			return "", append(errs, renderErr)
+		}
+		ref := m[1]
+		renderErr.Message = ref + " is not defined"
+		if !seen[renderErr] {
+			seen[renderErr] = true
+			errs = append(errs, renderErr)
+		}
+
+		// References relative to a rebound dot cannot be patched; stop here
+		patched, ok := withPlaceholder(data, ref)
+		if !ok {
+			return "", errs
+		}
+		data = patched
+	}
+	return "", errs
+}
+
+// withPlaceholder returns a copy of data in which the missing key of a field
+// chain such as ".a.b" or "$.a.b" is set: to an empty map when the chain
+// continues below it, and to an empty string otherwise. It reports false
+// when the chain does not start at the top-level data or has no missing key.
+func withPlaceholder(data map[string]any, ref string) (map[string]any, bool) {
+	ref = strings.TrimPrefix(ref, "$")
+	if !strings.HasPrefix(ref, ".") {
+		return nil, false
+	}
+	fields := strings.Split(ref[1:], ".")
+
+	root := copyMap(data)
+	current := root
+	for i, field := range fields {
+		value, ok := current[field]
+		if !ok {
+			if i == len(fields)-1 {
+				current[field] = ""
+			} else {
+				current[field] = map[string]any{}
+			}
+			return root, true
+		}
+		next, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		next = copyMap(next)
+		current[field] = next
+		current = next
+	}
+	return nil, false
+}
+
+func copyMap(m map[string]any) map[string]any {
+	copied := make(map[string]any, len(m)+1)
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func TestProcessTemplateDir_StrictReportsEveryUndefinedReference(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "{{ .slug }}")
+	writeTestFile(t, filepath.Join(src, "README.md"), "# {{ .name }}\n\n{{ .slgu }} by {{ .owner.email }}\n")
+	writeTestFile(t, filepath.Join(src, "cmd", "{{ .bin }}", "main.go"), "package main\n")
+	writeTestFile(t, filepath.Join(src, "raw.txt"), "{{/* no_render:start */}}\n{{ .a }}\n{{/* no_render:end */}}\n{{ .after }}\n")
+	writeTestFile(t, filepath.Join(root, PartialsDirName, "footer.txt"), "{{ .licence }}")
+	writeTestFile(t, filepath.Join(src, "LICENSE"), `{{ template "footer" . }}`)
+
+	inputs := map[string]any{"name": "shop", "owner": map[string]any{}}
+	opts := &config.TemplateOptions{Strict: true}
+	err := ProcessTemplateDir(src, t.TempDir(), inputs, opts)
+
+	var renderErrs *RenderErrors
+	if !errors.As(err, &renderErrs) {
+		t.Fatalf("expected RenderErrors, got %v", err)
+	}
+	var got []string
+	for _, renderErr := range renderErrs.Errors {
+		got = append(got, renderErr.String())
+	}
+	want := []string{
+		filepath.Join(PartialsDirName, "footer") + ":1:4: .licence is not defined",
+		"README.md:3:4: .slgu is not defined",
+		"README.md:3:25: .owner.email is not defined",
+		filepath.Join("cmd", "{{ .bin }}") + ":1:8: .bin is not defined",
+		"raw.txt:4:4: .after is not defined",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("undefined references:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestProcessTemplateDir_NotStrictRendersNoValue(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "README.md"), "{{ .slgu }}")
+
+	if err := ProcessTemplateDir(src, dst, map[string]any{}, nil); err != nil {
+		t.Fatalf("ProcessTemplateDir failed: %v", err)
+	}
+}
+
+func TestWithPlaceholder(t *testing.T) {
+	data := map[string]any{"owner": map[string]any{"name": "jane"}}
+
+	patched, ok := withPlaceholder(data, "$.owner.email")
+	if !ok {
+		t.Fatal("expected the chain to be patched")
+	}
+	if email := patched["owner"].(map[string]any)["email"]; email != "" {
+		t.Errorf("owner.email = %#v, want an empty string", email)
+	}
+	if _, ok := data["owner"].(map[string]any)["email"]; ok {
+		t.Error("the original data must not be changed")
+	}
+
+	if patched, _ := withPlaceholder(data, ".team.lead"); !reflect.DeepEqual(patched["team"], map[string]any{}) {
+		t.Errorf("team = %#v, want an empty map", patched["team"])
+	}
+	if _, ok := withPlaceholder(data, ".owner.name.first"); ok {
+		t.Error("a chain without a missing key cannot be patched")
+	}
+	if _, ok := withPlaceholder(data, "$x.name"); ok {
+		t.Error("a variable other than $ cannot be patched")
+	}
+}
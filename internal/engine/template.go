@@ -2,16 +2,26 @@ package engine
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"text/template"
 
 	"github.com/faradayfan/sygkro/internal/config"
+	"github.com/faradayfan/sygkro/internal/vfs"
+	"github.com/go-git/go-billy/v5"
 )
 
-func RenderString(tmplStr string, data map[string]string) (string, error) {
-	tmpl, err := template.New("render").Parse(tmplStr)
+func RenderString(tmplStr string, data map[string]any) (string, error) {
+	return RenderStringDelims(tmplStr, data, Delims{})
+}
+
+// RenderStringDelims is RenderString for a template using the given delimiters.
+func RenderStringDelims(tmplStr string, data map[string]any, delims Delims) (string, error) {
+	tmpl, err := template.New("render").Delims(delims.Left, delims.Right).Funcs(funcMap()).Parse(tmplStr)
 	if err != nil {
 		return "", fmt.Errorf("parsing template: %w", err)
 	}
@@ -22,59 +32,390 @@ func RenderString(tmplStr string, data map[string]string) (string, error) {
 	return buf.String(), nil
 }
 
-func ProcessTemplateDir(sourceDir, targetDir string, inputs map[string]string, opts *config.TemplateOptions) error {
-	return filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+// ProcessTemplateDir renders the template files under sourceDir into
+// targetDir with the engine selected by the options, and runs the formatters
+// of the options on them. Files that cannot be rendered or formatted are left
+// out, and the errors of all of them are returned together as *RenderErrors.
+// Files are rendered on as many goroutines as there are CPUs.
+func ProcessTemplateDir(sourceDir, targetDir string, inputs map[string]any, opts *config.TemplateOptions) error {
+	return ProcessTemplateDirJobs(sourceDir, targetDir, inputs, opts, 0)
+}
+
+// ProcessTemplateDirJobs is ProcessTemplateDir rendering at most
+// Workers(jobs) files at once. The output and the order of the errors are
+// the same whatever the number of jobs.
+func ProcessTemplateDirJobs(sourceDir, targetDir string, inputs map[string]any, opts *config.TemplateOptions, jobs int) error {
+	return ProcessTemplateFS(sourceDir, vfs.OS(targetDir), inputs, opts, jobs)
+}
+
+// ProcessTemplateFS is ProcessTemplateDirJobs rendering into the filesystem
+// target, such as vfs.Memory() for a render that is only compared.
+func ProcessTemplateFS(sourceDir string, target billy.Filesystem, inputs map[string]any, opts *config.TemplateOptions, jobs int) error {
+	eng, err := New(sourceDir, opts)
+	if err != nil {
+		return err
+	}
+	formatting, err := newFormatRules(opts)
+	if err != nil {
+		return err
+	}
+
+	r := &fileRenderer{engine: eng, target: target, formatting: formatting}
+	if opts != nil {
+		r.skipRender = newPathList(opts.SkipRender)
+		r.eol = opts.EOL
+	}
+
+	// The walk renders the names and creates the directories, parents before
+	// children, and queues the files for the workers. Errors found by the
+	// walk are kept with the file queued after them, so that joining the
+	// errors of the files in order lists them as a serial render would
+	var walkErrs renderErrors
+	var files []*fileJob
+	parsed := make(map[string]*parsedTemplate)
+	queued := make(map[string]*fileJob)
+	err = walkTemplate(sourceDir, inputs, opts, eng, &walkErrs, func(entry templateEntry) error {
+		if entry.info.IsDir() {
+			return target.MkdirAll(entry.renderedRelPath, entry.info.Mode())
 		}
 
-		relPath, err := filepath.Rel(sourceDir, path)
-		if err != nil {
-			return err
+		job := &fileJob{entry: entry, errs: walkErrs}
+		walkErrs = nil
+
+		// Files generated once per foreach element are parsed once
+		job.parsed = parsed[entry.relPath]
+		if job.parsed == nil {
+			job.parsed = &parsedTemplate{}
+			job.firstParse = true
+			parsed[entry.relPath] = job.parsed
 		}
 
-		renderedRelPath, err := RenderString(relPath, inputs)
+		// The last file rendered to a path wins, as in a serial render
+		if previous, ok := queued[entry.renderedRelPath]; ok {
+			previous.overwritten = true
+		}
+		queued[entry.renderedRelPath] = job
+
+		files = append(files, job)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := forEachJob(len(files), jobs, func(i int) error { return r.render(files[i]) }); err != nil {
+		return err
+	}
+
+	var errs renderErrors
+	for _, job := range files {
+		errs = append(errs, job.errs...)
+	}
+	errs = append(errs, walkErrs...)
+	return errs.err()
+}
+
+// fileJob is a file of the template queued for rendering.
+type fileJob struct {
+	entry       templateEntry
+	parsed      *parsedTemplate
+	firstParse  bool // the first job of the file, which reports its parse errors
+	overwritten bool // a later job renders to the same path
+	errs        renderErrors
+}
+
+// parsedTemplate is a file of the template, parsed by the first job that
+// needs it.
+type parsedTemplate struct {
+	once sync.Once
+	tmpl Template
+	err  error
+}
+
+// fileRenderer renders queued files. Its render method is called from many
+// goroutines at once.
+type fileRenderer struct {
+	engine     Engine
+	target     billy.Filesystem
+	skipRender pathList
+	formatting formatRules
+	eol        eolRules
+}
+
+// render writes the file of job, adding the errors of the file to job.errs.
+func (r *fileRenderer) render(job *fileJob) error {
+	entry := job.entry
+	targetPath := entry.renderedRelPath
+
+	// Symlinks are reproduced as symlinks, with their target rendered like a path
+	if entry.info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(entry.path)
 		if err != nil {
 			return err
 		}
+		rendered, err := r.engine.RenderPath(entry.relPath, target, false, entry.data)
+		if err != nil {
+			job.errs.add(entry.relPath, 0, err)
+			return nil
+		}
+		if job.overwritten {
+			return nil
+		}
+		return vfs.WriteSymlink(r.target, targetPath, rendered)
+	}
 
-		targetPath := filepath.Join(targetDir, renderedRelPath)
+	content, err := os.ReadFile(entry.path)
+	if err != nil {
+		return err
+	}
 
-		if info.IsDir() {
-			return os.MkdirAll(targetPath, info.Mode())
+	// Binary files such as images or jars are copied byte for byte.
+	if IsBinary(content) {
+		if job.overwritten {
+			return nil
 		}
+		return vfs.WriteFile(r.target, targetPath, content, entry.info.Mode())
+	}
 
-		if opts != nil {
-			for _, pattern := range opts.SkipRender {
-				if matched, _ := filepath.Match(pattern, relPath); matched {
-					// Copy the file without rendering.
-					content, err := os.ReadFile(path)
-					if err != nil {
-						return err
-					}
-					return os.WriteFile(targetPath, content, info.Mode())
-				}
+	// Text is rendered and formatted with "\n" line endings and no byte order
+	// mark, and the generated file gets back those of the template file
+	encoding := r.eol.encoding(targetPath, DetectEncoding(content))
+	content = Normalize(content)
+
+	if !r.skipRender.match(entry.relPath, false) {
+		job.parsed.once.Do(func() {
+			job.parsed.tmpl, job.parsed.err = r.engine.Parse(entry.relPath, string(content))
+		})
+		if job.parsed.err != nil {
+			if job.firstParse {
+				job.errs.add(entry.relPath, 0, job.parsed.err)
 			}
+			return nil
 		}
 
-		content, err := os.ReadFile(path)
+		rendered, err := job.parsed.tmpl.Render(entry.data)
 		if err != nil {
-			return err
+			job.errs.add(entry.relPath, 0, err)
+			return nil
 		}
+		content = []byte(rendered)
+	}
+
+	// Generated files are formatted whether or not they were rendered, as
+	// FormatDir formats them
+	formatted, err := r.formatting.format(entry.renderedRelPath, content)
+	if err != nil {
+		job.errs.add(entry.relPath, 0, err)
+		return nil
+	}
+	if job.overwritten {
+		return nil
+	}
+	return vfs.WriteFile(r.target, targetPath, encoding.Apply(formatted), entry.info.Mode())
+}
 
-		processed, rawMap, err := PreprocessRawBlocks(string(content))
+// RenderPaths returns the path, relative to the output directory, that each
+// file under sourceDir is rendered to with the given inputs. Paths are keyed
+// by the file's path in sourceDir, followed by the foreach items it was
+// generated for, e.g. "services/{{ .item }}/main.go[api]". Files that
+// ProcessTemplateDir leaves out are not included.
+func RenderPaths(sourceDir string, inputs map[string]any, opts *config.TemplateOptions) (map[string]string, error) {
+	eng, err := New(sourceDir, opts)
+	if err != nil {
+		return nil, err
+	}
 
-		if err != nil {
-			return err
+	paths := make(map[string]string)
+	var errs renderErrors
+	err = walkTemplate(sourceDir, inputs, opts, eng, &errs, func(entry templateEntry) error {
+		if !entry.info.IsDir() {
+			paths[entry.key] = entry.renderedRelPath
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paths, errs.err()
+}
 
-		rendered, err := RenderString(processed, inputs)
-		if err != nil {
+// templateEntry is a file or directory generated from the template.
+type templateEntry struct {
+	path            string // path in the template directory
+	relPath         string // path relative to the template directory
+	renderedRelPath string // path relative to the output directory
+	key             string // relPath plus the foreach items the entry was generated for
+	info            os.FileInfo
+	data            map[string]any // inputs, plus .item inside a foreach
+}
+
+// walkTemplate calls visit for every file and directory generated from
+// sourceDir, parents before children. Excluded paths and paths left out by
+// renderPath are skipped with everything below them, and paths matching a
+// foreach pattern are visited once per element of its list, with the element
+// as .item. Names are rendered with eng, and paths that cannot be rendered
+// are skipped and their errors added to errs.
+func walkTemplate(sourceDir string, inputs map[string]any, opts *config.TemplateOptions, eng Engine, errs *renderErrors, visit func(templateEntry) error) error {
+	ignored, err := readIgnoreFile(sourceDir)
+	if err != nil {
+		return err
+	}
+	var exclude []string
+	if opts != nil {
+		exclude = append(exclude, opts.Exclude...)
+	}
+
+	w := &templateWalker{
+		sourceDir: sourceDir,
+		opts:      opts,
+		exclude:   newPathList(append(exclude, ignored...)),
+		engine:    eng,
+		errs:      errs,
+		visit:     visit,
+	}
+	return w.walk(".", ".", inputs, true)
+}
+
+type templateWalker struct {
+	sourceDir string
+	opts      *config.TemplateOptions
+	exclude   pathList // exclude option followed by the .sygkroignore patterns
+	engine    Engine
+	errs      *renderErrors
+	visit     func(templateEntry) error
+}
+
+func (w *templateWalker) walk(relPath, key string, data map[string]any, expand bool) error {
+	path := filepath.Join(w.sourceDir, relPath)
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+
+	if relPath != "." && w.exclude.match(relPath, info.IsDir()) {
+		return nil
+	}
+
+	if expand && w.opts != nil && relPath != "." {
+		for _, pattern := range sortedKeys(w.opts.Foreach) {
+			expr := w.opts.Foreach[pattern]
+			if !matchPathExactly(pattern, relPath, info.IsDir()) {
+				continue
+			}
+			items, err := evalList(expr, data)
+			if err != nil {
+				w.errs.add(relPath, 0, fmt.Errorf("foreach %q: %w", pattern, err))
+				return nil
+			}
+			for _, item := range items {
+				itemData := make(map[string]any, len(data)+1)
+				for name, value := range data {
+					itemData[name] = value
+				}
+				itemData["item"] = item
+				itemKey := fmt.Sprintf("%s[%v]", key, item)
+				if err := w.walk(relPath, itemKey, itemData, false); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	renderedRelPath, ok := w.renderPath(relPath, info.IsDir(), data)
+	if !ok {
+		return nil
+	}
+
+	entry := templateEntry{path: path, relPath: relPath, renderedRelPath: renderedRelPath, key: key, info: info, data: data}
+	if err := w.visit(entry); err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, child := range entries {
+		if relPath == "." && (child.Name() == PartialsDirName || child.Name() == IgnoreFileName) {
+			continue
+		}
+		childRelPath := filepath.Join(relPath, child.Name())
+		childKey := filepath.Join(key, child.Name())
+		if err := w.walk(childRelPath, childKey, data, true); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		finalOutput := PostprocessRawBlocks(rendered, rawMap)
+// evalList evaluates an expression such as `.services` to a list.
+func evalList(expr string, data map[string]any) ([]any, error) {
+	expr = strings.TrimSpace(expr)
+	expr = strings.TrimSuffix(strings.TrimPrefix(expr, "{{"), "}}")
 
-		return os.WriteFile(targetPath, []byte(finalOutput), info.Mode())
-	})
+	rendered, err := RenderString("{{ toJson ("+expr+") }}", data)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []any
+	if err := json.Unmarshal([]byte(rendered), &items); err != nil {
+		return nil, fmt.Errorf("%s is not a list", strings.TrimSpace(expr))
+	}
+	return items, nil
+}
+
+// renderPath renders a path relative to the template directory segment by
+// segment. It reports false when the path is left out of the output: a
+// segment renders to an empty name, or an include_if condition whose pattern
+// matches the path does not hold, checked in sorted order of the patterns. Either leaves out everything below the
+// path too, as do errors, which are added to the walker's errors.
+func (w *templateWalker) renderPath(relPath string, isDir bool, inputs map[string]any) (string, bool) {
+	opts := w.opts
+	if relPath == "." {
+		return relPath, true
+	}
+
+	if opts != nil {
+		for _, pattern := range sortedKeys(opts.IncludeIf) {
+			if !matchPath(pattern, relPath, isDir) {
+				continue
+			}
+			ok, err := EvalCondition(opts.IncludeIf[pattern], inputs)
+			if err != nil {
+				w.errs.add(relPath, 0, fmt.Errorf("include_if %q: %w", pattern, err))
+				return "", false
+			}
+			if !ok {
+				return "", false
+			}
+		}
+	}
+
+	// Each segment is rendered as part of the path it ends, which selects
+	// options such as the delimiters
+	segments := strings.Split(relPath, string(filepath.Separator))
+	prefix := ""
+	for i, segment := range segments {
+		offset := len(prefix)
+		if offset > 0 {
+			offset++ // the separator
+		}
+		prefix = filepath.Join(prefix, segment)
+		// Every segment but the last names a directory
+		rendered, err := w.engine.RenderPath(prefix, segment, isDir || i < len(segments)-1, inputs)
+		if err != nil {
+			w.errs.add(prefix, offset, err)
+			return "", false
+		}
+		if strings.TrimSpace(rendered) == "" {
+			return "", false
+		}
+		segments[i] = rendered
+	}
+	return filepath.Join(segments...), true
 }
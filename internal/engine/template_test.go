@@ -1,8 +1,11 @@
 package engine
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/faradayfan/sygkro/internal/config"
@@ -10,7 +13,7 @@ import (
 
 func TestRenderString_Basic(t *testing.T) {
 	tmpl := "Hello, {{.name}}!"
-	data := map[string]string{"name": "World"}
+	data := map[string]any{"name": "World"}
 	out, err := RenderString(tmpl, data)
 	if err != nil {
 		t.Fatalf("RenderString failed: %v", err)
@@ -23,7 +26,7 @@ func TestRenderString_Basic(t *testing.T) {
 
 func TestRenderString_Error(t *testing.T) {
 	tmpl := "Hello, {{.name" // malformed
-	data := map[string]string{"name": "World"}
+	data := map[string]any{"name": "World"}
 	_, err := RenderString(tmpl, data)
 	if err == nil {
 		t.Errorf("expected error for malformed template")
@@ -40,7 +43,7 @@ func TestProcessTemplateDir_BasicFlow(t *testing.T) {
 	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
 		t.Fatalf("failed to write template file: %v", err)
 	}
-	inputs := map[string]string{"who": "Alice"}
+	inputs := map[string]any{"who": "Alice"}
 	opts := &config.TemplateOptions{}
 	err := ProcessTemplateDir(src, dst, inputs, opts)
 	if err != nil {
@@ -68,7 +71,7 @@ func TestProcessTemplateDir_SkipRender(t *testing.T) {
 	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
 		t.Fatalf("failed to write static file: %v", err)
 	}
-	inputs := map[string]string{"should_not_render": "RENDERED"}
+	inputs := map[string]any{"should_not_render": "RENDERED"}
 	opts := &config.TemplateOptions{SkipRender: []string{"static.txt"}}
 	err := ProcessTemplateDir(src, dst, inputs, opts)
 	if err != nil {
@@ -85,3 +88,361 @@ func TestProcessTemplateDir_SkipRender(t *testing.T) {
 		t.Errorf("skip render failed: got %q, want %q", string(data), content)
 	}
 }
+
+func TestProcessTemplateDir_SkipRenderPatterns(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	content := "{{ .name }}"
+	files := []string{
+		filepath.Join("static", "js", "app.js"),
+		filepath.Join("static", "js", "config.js"),
+		filepath.Join("src", "main.go"),
+	}
+	for _, name := range files {
+		writeTestFile(t, filepath.Join(src, name), content)
+	}
+
+	opts := &config.TemplateOptions{SkipRender: []string{"static/**", "!static/js/config.js"}}
+	if err := ProcessTemplateDir(src, dst, map[string]any{"name": "shop"}, opts); err != nil {
+		t.Fatalf("ProcessTemplateDir failed: %v", err)
+	}
+
+	want := map[string]string{files[0]: content, files[1]: "shop", files[2]: "shop"}
+	for name, wantContent := range want {
+		data, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatalf("output file not found: %v", err)
+		}
+		if string(data) != wantContent {
+			t.Errorf("%s = %q, want %q", name, string(data), wantContent)
+		}
+	}
+}
+
+func TestProcessTemplateDir_Exclude(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	files := []string{
+		filepath.Join("testdata", "input.golden"),
+		filepath.Join("src", "main.go"),
+		filepath.Join("src", "main.snap"),
+		filepath.Join("src", "keep.snap"),
+		"notes.draft.md",
+	}
+	for _, name := range files {
+		writeTestFile(t, filepath.Join(src, name), "x")
+	}
+	writeTestFile(t, filepath.Join(src, IgnoreFileName), "# snapshots\n*.snap\n!keep.snap\n")
+
+	opts := &config.TemplateOptions{Exclude: []string{"testdata/", "*.draft.md"}}
+	if err := ProcessTemplateDir(src, dst, nil, opts); err != nil {
+		t.Fatalf("ProcessTemplateDir failed: %v", err)
+	}
+
+	want := map[string]bool{
+		"testdata":                        false,
+		filepath.Join("src", "main.go"):   true,
+		filepath.Join("src", "main.snap"): false,
+		filepath.Join("src", "keep.snap"): true,
+		"notes.draft.md":                  false,
+		IgnoreFileName:                    false,
+	}
+	for name, wantExists := range want {
+		_, err := os.Stat(filepath.Join(dst, name))
+		if got := err == nil; got != wantExists {
+			t.Errorf("%s exists = %v, want %v", name, got, wantExists)
+		}
+	}
+
+	paths, err := RenderPaths(src, nil, opts)
+	if err != nil {
+		t.Fatalf("RenderPaths failed: %v", err)
+	}
+	if len(paths) != 2 {
+		t.Errorf("RenderPaths should leave out excluded files, got %v", paths)
+	}
+}
+
+func TestProcessTemplateDir_KeepsModesAndSymlinks(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "scripts", "build.sh"), "echo {{ .name }}\n")
+	if err := os.Chmod(filepath.Join(src, "scripts", "build.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(src, "docs", "{{ .name }}.md"), "# {{ .name }}\n")
+	if err := os.Symlink("docs/{{ .name }}.md", filepath.Join(src, "README.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("scripts", filepath.Join(src, "bin")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ProcessTemplateDir(src, dst, map[string]any{"name": "shop"}, nil); err != nil {
+		t.Fatalf("ProcessTemplateDir failed: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dst, "scripts", "build.sh"))
+	if err != nil {
+		t.Fatalf("output file not found: %v", err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("build.sh mode = %04o, want 0755", info.Mode().Perm())
+	}
+
+	for name, want := range map[string]string{"README.md": "docs/shop.md", "bin": "scripts"} {
+		target, err := os.Readlink(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatalf("%s is not a symlink: %v", name, err)
+		}
+		if target != want {
+			t.Errorf("%s -> %s, want %s", name, target, want)
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "README.md")); err != nil || string(data) != "# shop\n" {
+		t.Errorf("README.md reads %q (%v)", string(data), err)
+	}
+}
+
+func TestProcessTemplateDir_ReportsEveryRenderError(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "a.txt"), "ok\n{{ .name \n")
+	writeTestFile(t, filepath.Join(src, "b.txt"), "{{ .name }}\n  {{ upper 1 }}\n")
+	writeTestFile(t, filepath.Join(src, "{{ .name | nope }}", "c.txt"), "x")
+	writeTestFile(t, filepath.Join(src, "d.txt"), "{{ .name }}")
+
+	err := ProcessTemplateDir(src, dst, map[string]any{"name": "shop"}, nil)
+
+	var renderErrs *RenderErrors
+	if !errors.As(err, &renderErrs) {
+		t.Fatalf("expected RenderErrors, got %v", err)
+	}
+	var files []string
+	for _, renderErr := range renderErrs.Errors {
+		files = append(files, fmt.Sprintf("%s:%d:%d", renderErr.File, renderErr.Line, renderErr.Column))
+	}
+	want := []string{"a.txt:3:0", "b.txt:2:12", "{{ .name | nope }}:1:0"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("errors at %v, want %v\n%v", files, want, err)
+	}
+
+	// Files without errors are still rendered
+	if data, err := os.ReadFile(filepath.Join(dst, "d.txt")); err != nil || string(data) != "shop" {
+		t.Errorf("d.txt = %q (%v)", string(data), err)
+	}
+}
+
+func TestRenderString_TypedInputs(t *testing.T) {
+	tmpl := "{{ if .use_docker }}docker{{ end }}{{ range .services }} {{ . }}{{ end }} {{ .port }}"
+	data := map[string]any{
+		"use_docker": true,
+		"services":   []any{"api", "worker"},
+		"port":       8080,
+	}
+	out, err := RenderString(tmpl, data)
+	if err != nil {
+		t.Fatalf("RenderString failed: %v", err)
+	}
+	want := "docker api worker 8080"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestRenderPaths(t *testing.T) {
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "cmd", "{{ .slug }}"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"README.md", filepath.Join("cmd", "{{ .slug }}", "main.go")} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := RenderPaths(src, map[string]any{"slug": "api"}, nil)
+	if err != nil {
+		t.Fatalf("RenderPaths failed: %v", err)
+	}
+
+	want := map[string]string{
+		"README.md": "README.md",
+		filepath.Join("cmd", "{{ .slug }}", "main.go"): filepath.Join("cmd", "api", "main.go"),
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+	for source, rendered := range want {
+		if paths[source] != rendered {
+			t.Errorf("paths[%q] = %q, want %q", source, paths[source], rendered)
+		}
+	}
+}
+
+func TestProcessTemplateDir_EmptySegmentSkipsSubtree(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	files := []string{
+		filepath.Join("{{ if .use_docker }}docker{{ end }}", "Dockerfile"),
+		"{{ if .use_docker }}.dockerignore{{ end }}",
+		filepath.Join("{{ .pkg }}", "main.go"),
+	}
+	for _, name := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(src, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(src, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inputs := map[string]any{"use_docker": false, "pkg": "app"}
+	if err := ProcessTemplateDir(src, dst, inputs, nil); err != nil {
+		t.Fatalf("ProcessTemplateDir failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "app" {
+		t.Errorf("expected only app in the output, got %v", entries)
+	}
+}
+
+func TestProcessTemplateDir_IncludeIf(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	for _, name := range []string{"Dockerfile", filepath.Join("charts", "values.yaml"), "README.md"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(src, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(src, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := &config.TemplateOptions{IncludeIf: map[string]string{
+		"Dockerfile": ".use_docker",
+		"charts":     `eq .deploy "helm"`,
+	}}
+	inputs := map[string]any{"use_docker": true, "deploy": "none"}
+	if err := ProcessTemplateDir(src, dst, inputs, opts); err != nil {
+		t.Fatalf("ProcessTemplateDir failed: %v", err)
+	}
+
+	for name, want := range map[string]bool{"Dockerfile": true, "README.md": true, "charts": false} {
+		_, err := os.Stat(filepath.Join(dst, name))
+		if got := err == nil; got != want {
+			t.Errorf("%s exists = %v, want %v", name, got, want)
+		}
+	}
+
+	paths, err := RenderPaths(src, inputs, opts)
+	if err != nil {
+		t.Fatalf("RenderPaths failed: %v", err)
+	}
+	if _, ok := paths[filepath.Join("charts", "values.yaml")]; ok || len(paths) != 2 {
+		t.Errorf("RenderPaths should leave out excluded files, got %v", paths)
+	}
+}
+
+func TestProcessTemplateDir_IncludeIfOverlappingPatterns(t *testing.T) {
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "docs", "guide.md"), "x")
+
+	opts := &config.TemplateOptions{IncludeIf: map[string]string{
+		"docs/*.md": "eq .docs",
+		"*.md":      ".docs",
+	}}
+
+	// The conditions are checked in sorted order of their patterns, so the
+	// false one leaves the file out before the broken one is evaluated
+	for range 20 {
+		dst := t.TempDir()
+		if err := ProcessTemplateDir(src, dst, map[string]any{"docs": false}, opts); err != nil {
+			t.Fatalf("ProcessTemplateDir failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dst, "docs", "guide.md")); !os.IsNotExist(err) {
+			t.Fatal("expected docs/guide.md to be left out")
+		}
+	}
+}
+
+func TestProcessTemplateDir_Foreach(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	serviceDir := filepath.Join(src, "services", "{{ .item }}")
+	if err := os.MkdirAll(serviceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(serviceDir, "main.go"), []byte("// {{ .item }} of {{ .name }}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &config.TemplateOptions{Foreach: map[string]string{
+		filepath.Join("services", "{{ .item }}"): ".services",
+	}}
+	inputs := map[string]any{"name": "shop", "services": []any{"api", "worker"}}
+	if err := ProcessTemplateDir(src, dst, inputs, opts); err != nil {
+		t.Fatalf("ProcessTemplateDir failed: %v", err)
+	}
+
+	for _, item := range []string{"api", "worker"} {
+		data, err := os.ReadFile(filepath.Join(dst, "services", item, "main.go"))
+		if err != nil {
+			t.Fatalf("expected a file for %s: %v", item, err)
+		}
+		if want := "// " + item + " of shop\n"; string(data) != want {
+			t.Errorf("%s content = %q, want %q", item, string(data), want)
+		}
+	}
+
+	paths, err := RenderPaths(src, inputs, opts)
+	if err != nil {
+		t.Fatalf("RenderPaths failed: %v", err)
+	}
+	key := filepath.Join("services", "{{ .item }}") + "[worker]" + string(filepath.Separator) + "main.go"
+	if paths[key] != filepath.Join("services", "worker", "main.go") {
+		t.Errorf("RenderPaths = %v", paths)
+	}
+}
+
+func TestProcessTemplateDir_ForeachOverlappingPatterns(t *testing.T) {
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "svc", "{{ .item }}1.txt"), "{{ .item }}\n")
+
+	opts := &config.TemplateOptions{Foreach: map[string]string{
+		"svc/*.txt": ".a",
+		"*.txt":     ".b",
+	}}
+	inputs := map[string]any{"a": []any{"a"}, "b": []any{"b"}}
+
+	// The first pattern in sorted order wins, however the map is ordered
+	for range 20 {
+		dst := t.TempDir()
+		if err := ProcessTemplateDir(src, dst, inputs, opts); err != nil {
+			t.Fatalf("ProcessTemplateDir failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dst, "svc", "b1.txt")); err != nil {
+			t.Fatalf("expected the *.txt pattern to apply: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dst, "svc", "a1.txt")); !os.IsNotExist(err) {
+			t.Fatal("expected svc/*.txt not to apply")
+		}
+	}
+}
+
+func TestProcessTemplateDir_ForeachRequiresAList(t *testing.T) {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "{{ .item }}.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &config.TemplateOptions{Foreach: map[string]string{"{{ .item }}.txt": ".name"}}
+	err := ProcessTemplateDir(src, t.TempDir(), map[string]any{"name": "shop"}, opts)
+	if err == nil {
+		t.Error("expected error for a foreach over a non-list")
+	}
+}
@@ -2,96 +2,116 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/faradayfan/sygkro/internal/config"
 	"github.com/faradayfan/sygkro/internal/engine"
+	"github.com/faradayfan/sygkro/internal/vfs"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	udiff "github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
 // ComputeDiff renders the template using the inputs from the sync configuration into
-// a temporary directory ("ideal") and computes a unified diff between that rendered output
-// and the actual project directory.
+// memory ("ideal") and computes a unified diff from the project's version of each
+// rendered file to the rendered one.
 func ComputeDiff(templateDir string, projectDir string, idealRevision string, syncConfig *config.SyncConfig) (string, error) {
-	// create a temporary directory for the current state
-	currentTmpDir, err := os.MkdirTemp("", "sygkro-current-*")
+	// read the template config file for the ideal revision
+	idealTemplateConfig, err := config.ReadTemplateConfig(path.Join(templateDir, config.TemplateConfigFileName))
 	if err != nil {
-		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+		return "", fmt.Errorf("failed to read template config file: %w", err)
 	}
 
-	defer func() {
-		if err := os.RemoveAll(currentTmpDir); err != nil {
-			fmt.Printf("failed to remove current temporary directory: %v\n", err)
-		}
-	}()
+	expectedSubDir := filepath.Join(templateDir, "{{ .slug }}")
 
-	// create a temporary directory for the ideal state
-	idealTmpDir, err := os.MkdirTemp("", "sygkro-ideal-*")
-	if err != nil {
-		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	// render the template the ideal revision into memory
+	ideal := vfs.Memory()
+	if err := engine.ProcessTemplateFS(expectedSubDir, ideal, idealTemplateConfig.RenderData(syncConfig.Inputs), idealTemplateConfig.Options, 0); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
 	}
 
-	defer func() {
-		if err := os.RemoveAll(idealTmpDir); err != nil {
-			fmt.Printf("failed to remove ideal temporary directory: %v\n", err)
-		}
-	}()
+	// only the files the template renders are compared
+	idealFiles, err := vfs.Files(ideal)
+	if err != nil {
+		return "", fmt.Errorf("failed to walk rendered template: %w", err)
+	}
 
-	// read the template config file for the ideal revision
-	idealTemplateConfig, err := config.ReadTemplateConfig(path.Join(templateDir, config.TemplateConfigFileName))
+	// compute the diff between the project and the ideal state
+	diff, err := unifiedDiff(vfs.OS(projectDir), ideal, idealFiles)
 	if err != nil {
-		return "", fmt.Errorf("failed to read template config file: %w", err)
+		return "", fmt.Errorf("failed to compute diff: %w", err)
 	}
 
-	expectedSubDir := filepath.Join(templateDir, "{{ .slug }}")
+	return diff, nil
+}
 
-	// render the template the ideal revision into the ideal temporary directory
-	if err := engine.ProcessTemplateDir(expectedSubDir, idealTmpDir, syncConfig.Inputs, idealTemplateConfig.Options); err != nil {
-		return "", fmt.Errorf("failed to render template: %w", err)
+// ComputeTemplateDiff renders the template at both the old and new versions
+// and returns a unified diff showing only what changed in the template.
+// This is useful for previewing what a sync will bring in.
+//
+// templateDir is the new version of the template and oldTemplateDir a
+// checkout of the previously synced version (use GitWorktree), or empty when
+// the project was never synced. The old version is rendered with oldInputs
+// and the new one with newInputs, prepared as project sync prepares them.
+// Both versions are rendered into memory at the same time, with at most jobs
+// files at once each.
+func ComputeTemplateDiff(templateDir, oldTemplateDir string, oldInputs, newInputs map[string]any, jobs int) (string, error) {
+	newTemplateConfig, err := config.ReadTemplateConfig(filepath.Join(templateDir, config.TemplateConfigFileName))
+	if err != nil {
+		return "", fmt.Errorf("failed to read template config: %w", err)
 	}
 
-	// for every file in the templateDir, copy the files with the same name from the projectDir into currentTmpDir
-	walkErr := filepath.Walk(idealTmpDir, func(path string, info os.FileInfo, err error) error {
-		// print the path
-		if err != nil {
-			return err
-		}
-
-		// remove the idealTmpDir prefix from the path
-		relPath := strings.TrimPrefix(path, idealTmpDir+string(os.PathSeparator))
+	newRender := vfs.Memory()
+	oldRender := vfs.Memory()
 
-		projectDifFilePath := filepath.Join(projectDir, relPath)
-		currentTmpDirFilePath := filepath.Join(currentTmpDir, relPath)
+	renderOpts := RenderOptions{Jobs: jobs}
 
-		// if the file is a directory, create the directory in the currentTmpDir
-		if info.IsDir() {
-			return os.MkdirAll(filepath.Join(currentTmpDir, relPath), info.Mode())
+	// Render NEW template (current HEAD)
+	renders := []func() error{func() error {
+		if err := RenderTemplate(templateDir, newRender, newInputs, renderOpts); err != nil {
+			return fmt.Errorf("failed to render new template: %w", err)
 		}
+		return nil
+	}}
 
-		if _, err := os.Stat(projectDifFilePath); err == nil {
-			// if the file exists in the projectDir
-			// copy it to the currentTmpDir
-			content, err := os.ReadFile(projectDifFilePath)
-			if err != nil {
-				return err
+	// Render OLD template
+	if oldTemplateDir != "" {
+		// Formatted like the new version, as project sync formats it
+		renders = append(renders, func() error {
+			oldOpts := RenderOptions{Jobs: jobs, FormatAs: newTemplateConfig}
+			if err := RenderTemplate(oldTemplateDir, oldRender, oldInputs, oldOpts); err != nil {
+				return fmt.Errorf("failed to render old template: %w", err)
 			}
-			return os.WriteFile(currentTmpDirFilePath, content, info.Mode())
-		} else if os.IsNotExist(err) {
 			return nil
-		} else {
-			return err
-		}
-	})
+		})
+	}
+	// If there is no old version (first sync), oldRender stays empty — everything shows as added
+	if err := RenderConcurrently(renders...); err != nil {
+		return "", err
+	}
 
-	if walkErr != nil {
-		return "", fmt.Errorf("failed to walk template directory: %w", walkErr)
+	paths, err := vfs.Files(oldRender)
+	if err != nil {
+		return "", fmt.Errorf("failed to walk old template: %w", err)
+	}
+	newPaths, err := vfs.Files(newRender)
+	if err != nil {
+		return "", fmt.Errorf("failed to walk new template: %w", err)
+	}
+	for p := range newPaths {
+		paths[p] = true
 	}
 
-	// compute the diff between the current and ideal temporary directories
-	diff, err := gitDiff(currentTmpDir, idealTmpDir)
+	diff, err := unifiedDiff(oldRender, newRender, paths)
 	if err != nil {
 		return "", fmt.Errorf("failed to compute diff: %w", err)
 	}
@@ -99,45 +119,155 @@ func ComputeDiff(templateDir string, projectDir string, idealRevision string, sy
 	return diff, nil
 }
 
-// ComputeTemplateDiff renders the template at both the old and new versions
-// and returns a unified diff showing only what changed in the template.
-// This is useful for previewing what a sync will bring in.
-//
-// templateDir should be a cloned repo with full history (use GetTemplateDirForSync).
-// oldVersion is the commit SHA of the previously synced template version.
-func ComputeTemplateDiff(templateDir string, oldVersion string, syncConfig *config.SyncConfig) (string, error) {
-	// Render NEW template (current HEAD)
-	newTmpDir, err := os.MkdirTemp("", "sygkro-diff-new-*")
-	if err != nil {
-		return "", fmt.Errorf("failed to create temp dir: %w", err)
+// unifiedDiff returns a git-style unified diff from the files at paths in
+// from to those in to, in path order. A path missing on one side is a file
+// added or deleted; unchanged files are left out.
+func unifiedDiff(from, to billy.Filesystem, paths map[string]bool) (string, error) {
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
 	}
-	defer os.RemoveAll(newTmpDir)
+	sort.Strings(sorted)
 
-	if err := RenderTemplateAtPath(templateDir, newTmpDir, syncConfig.Inputs); err != nil {
-		return "", fmt.Errorf("failed to render new template: %w", err)
+	p := &patch{}
+	for _, relPath := range sorted {
+		fromFile, err := readDiffFile(from, relPath)
+		if err != nil {
+			return "", err
+		}
+		toFile, err := readDiffFile(to, relPath)
+		if err != nil {
+			return "", err
+		}
+		if fromFile == nil && toFile == nil {
+			continue
+		}
+		if fromFile != nil && toFile != nil && fromFile.hash == toFile.hash && fromFile.mode == toFile.mode {
+			continue
+		}
+		p.filePatches = append(p.filePatches, newFilePatch(fromFile, toFile))
 	}
 
-	// Render OLD template
-	oldTmpDir, err := os.MkdirTemp("", "sygkro-diff-old-*")
+	var out strings.Builder
+	encoder := fdiff.NewUnifiedEncoder(&out, fdiff.DefaultContextLines).
+		SetSrcPrefix("upstream-template-old/").
+		SetDstPrefix("upstream-template-new/")
+	if err := encoder.Encode(p); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// diffFile is a file on one side of a diff: a regular file, or a symlink
+// whose content is its target.
+type diffFile struct {
+	path    string
+	mode    filemode.FileMode
+	hash    plumbing.Hash
+	content []byte
+}
+
+func (f *diffFile) Hash() plumbing.Hash     { return f.hash }
+func (f *diffFile) Mode() filemode.FileMode { return f.mode }
+func (f *diffFile) Path() string            { return f.path }
+
+// readDiffFile reads the file at relPath in fs, or returns nil when there
+// is none. Text is read with "\n" line endings and no byte order mark.
+func readDiffFile(fs billy.Filesystem, relPath string) (*diffFile, error) {
+	info, err := fs.Lstat(relPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp dir: %w", err)
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, nil
 	}
-	defer os.RemoveAll(oldTmpDir)
 
-	if oldVersion != "" {
-		if err := GitCheckout(templateDir, oldVersion); err != nil {
-			return "", fmt.Errorf("failed to checkout old version %s: %w", oldVersion, err)
-		}
-		if err := RenderTemplateAtPath(templateDir, oldTmpDir, syncConfig.Inputs); err != nil {
-			return "", fmt.Errorf("failed to render old template: %w", err)
+	content, err := vfs.ReadContent(fs, relPath)
+	if err != nil {
+		return nil, err
+	}
+	// Line endings and byte order marks are not differences, as sync keeps
+	// those of the project
+	if info.Mode().IsRegular() && !engine.IsBinary(content) {
+		content = engine.Normalize(content)
+	}
+	mode, err := filemode.NewFromOSFileMode(info.Mode())
+	if err != nil {
+		return nil, err
+	}
+	return &diffFile{
+		path:    filepath.ToSlash(relPath),
+		mode:    mode,
+		hash:    plumbing.ComputeHash(plumbing.BlobObject, content),
+		content: content,
+	}, nil
+}
+
+type patch struct {
+	filePatches []fdiff.FilePatch
+}
+
+func (p *patch) FilePatches() []fdiff.FilePatch { return p.filePatches }
+func (p *patch) Message() string                { return "" }
+
+type filePatch struct {
+	from, to *diffFile
+	binary   bool
+	chunks   []fdiff.Chunk
+}
+
+// newFilePatch compares from and to, either of which may be nil.
+func newFilePatch(from, to *diffFile) *filePatch {
+	fp := &filePatch{from: from, to: to}
+	var fromContent, toContent []byte
+	if from != nil {
+		fromContent = from.content
+	}
+	if to != nil {
+		toContent = to.content
+	}
+	fp.binary = engine.IsBinary(fromContent) || engine.IsBinary(toContent)
+	if fp.binary {
+		return fp
+	}
+
+	for _, d := range udiff.Do(string(fromContent), string(toContent)) {
+		op := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
 		}
+		fp.chunks = append(fp.chunks, chunk{content: d.Text, op: op})
 	}
-	// If oldVersion is empty (first sync), oldTmpDir stays empty — everything shows as added
+	return fp
+}
 
-	diff, err := gitDiff(oldTmpDir, newTmpDir)
-	if err != nil {
-		return "", fmt.Errorf("failed to compute diff: %w", err)
+func (fp *filePatch) IsBinary() bool { return fp.binary }
+
+// Files returns nil interfaces, not nil pointers, for missing files, as the
+// encoder compares them with nil.
+func (fp *filePatch) Files() (fdiff.File, fdiff.File) {
+	var from, to fdiff.File
+	if fp.from != nil {
+		from = fp.from
 	}
+	if fp.to != nil {
+		to = fp.to
+	}
+	return from, to
+}
 
-	return diff, nil
+func (fp *filePatch) Chunks() []fdiff.Chunk { return fp.chunks }
+
+type chunk struct {
+	content string
+	op      fdiff.Operation
 }
+
+func (c chunk) Content() string       { return c.content }
+func (c chunk) Type() fdiff.Operation { return c.op }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/faradayfan/sygkro/internal/config"
 	"github.com/faradayfan/sygkro/internal/engine"
+	"github.com/faradayfan/sygkro/internal/vfs"
 )
 
 func contains(s, substr string) bool {
@@ -18,7 +19,7 @@ func TestComputeDiff_ProjectCreateFlow(t *testing.T) {
 	// Create a template directory and config
 	templateDir := t.TempDir()
 	templateName := "test-template"
-	templateInputs := map[string]string{
+	templateInputs := map[string]any{
 		"name":        "my-project",
 		"slug":        "my-project",
 		"description": "A new project created by sygkro",
@@ -33,7 +34,7 @@ func TestComputeDiff_ProjectCreateFlow(t *testing.T) {
 		Name:        templateName,
 		Description: "A new template created by sygkro",
 		Templating: config.TemplatingConfig{
-			Inputs: templateInputs,
+			Inputs: inputSpecs(templateInputs),
 		},
 	}
 	err := templateConfig.Write(configFilePath)
@@ -76,3 +77,30 @@ func TestComputeDiff_ProjectCreateFlow(t *testing.T) {
 		t.Errorf("diff output does not contain expected modification")
 	}
 }
+
+func TestUnifiedDiff_IgnoresLineEndingsAndBOM(t *testing.T) {
+	from := vfs.Memory()
+	to := vfs.Memory()
+	for path, contents := range map[string][2]string{
+		"same.txt":    {"\xef\xbb\xbfa\r\nb\r\n", "a\nb\n"},
+		"changed.txt": {"a\r\nb\r\n", "a\nc\n"},
+	} {
+		if err := vfs.WriteFile(from, path, []byte(contents[0]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := vfs.WriteFile(to, path, []byte(contents[1]), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	diff, err := unifiedDiff(from, to, map[string]bool{"same.txt": true, "changed.txt": true})
+	if err != nil {
+		t.Fatalf("unifiedDiff failed: %v", err)
+	}
+	if contains(diff, "same.txt") {
+		t.Errorf("expected no diff for same.txt, got:\n%s", diff)
+	}
+	if !contains(diff, "-b\n+c\n") || contains(diff, "\r") {
+		t.Errorf("expected a line diff without carriage returns, got %q", diff)
+	}
+}
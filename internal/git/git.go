@@ -3,8 +3,9 @@ package git
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"os/exec"
-	"strings"
+	"path/filepath"
 )
 
 func runCommand(execDir string, command string, args ...string) (string, error) {
@@ -34,30 +35,27 @@ func GitCheckout(repoPath string, commitish string) error {
 	return nil
 }
 
-func gitDiff(current string, ideal string) (string, error) {
-	// Prepare the git diff command.
-	args := []string{
-		"--no-pager",
-		"-c",
-		"diff.noprefix=",
-		"diff",
-		"--no-index",
-		"--relative",
-		"--binary",
-		"--src-prefix=upstream-template-old/",
-		"--dst-prefix=upstream-template-new/",
-		"--no-ext-diff",
-		"--no-color",
-		current,
-		ideal,
+// GitWorktree checks commitish out into a new temporary directory, a
+// worktree of the repo at repoPath, so that another version of a template
+// can be read without switching the checkout of repoPath. cleanup removes
+// the worktree.
+func GitWorktree(repoPath string, commitish string) (string, func(), error) {
+	tmpDir, err := os.MkdirTemp("", "sygkro-worktree-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary directory: %w", err)
 	}
-	stdOut, _ := runCommand(current, "git", args...)
-
-	oldProject := fmt.Sprintf("upstream-template-old%s", current)
-	newProject := fmt.Sprintf("upstream-template-new%s", ideal)
+	// git worktree add creates the directory itself
+	dir := filepath.Join(tmpDir, "template")
 
-	fixedDiff := strings.ReplaceAll(stdOut, oldProject, "upstream-template-old")
-	fixedDiff = strings.ReplaceAll(fixedDiff, newProject, "upstream-template-new")
+	stdOut, err := runCommand(repoPath, "git", "worktree", "add", "--detach", dir, commitish)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return "", nil, fmt.Errorf("git worktree add failed: %w: %s", err, stdOut)
+	}
 
-	return fixedDiff, nil
+	cleanup := func() {
+		_, _ = runCommand(repoPath, "git", "worktree", "remove", "--force", dir)
+		os.RemoveAll(tmpDir)
+	}
+	return dir, cleanup, nil
 }
@@ -6,6 +6,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+
+	"github.com/faradayfan/sygkro/internal/config"
+	"github.com/faradayfan/sygkro/internal/engine"
+	"github.com/faradayfan/sygkro/internal/vfs"
+	"github.com/go-git/go-billy/v5"
 )
 
 // MergeStatus represents the outcome of merging a single file.
@@ -24,6 +29,7 @@ type MergeFileResult struct {
 	RelPath      string      // Relative path within the project
 	Status       MergeStatus // Outcome of the merge
 	ConflictPath string      // Path to .sygkro-conflict file if Status == MergeConflict
+	Mode         os.FileMode // Permissions the template gives the file, if they changed or it is new; 0 otherwise
 }
 
 // MergeResult represents the outcome of merging all template files.
@@ -41,12 +47,20 @@ type MergeResult struct {
 // For each file, it determines the appropriate action based on which
 // directories contain the file and whether contents have changed.
 func ThreeWayMerge(baseDir, oursDir, theirsDir string) (*MergeResult, error) {
-	baseFiles, err := collectFiles(baseDir)
+	return ThreeWayMergeFS(vfs.OS(baseDir), vfs.OS(oursDir), vfs.OS(theirsDir), nil)
+}
+
+// ThreeWayMergeFS is ThreeWayMerge reading each side from a filesystem, so
+// that the rendered templates can be kept in memory. opts are the options of
+// the new template version, whose eol patterns override the line endings of
+// the project's files; they may be nil.
+func ThreeWayMergeFS(base, ours, theirs billy.Filesystem, opts *config.TemplateOptions) (*MergeResult, error) {
+	baseFiles, err := vfs.Files(base)
 	if err != nil {
 		return nil, fmt.Errorf("failed to collect base files: %w", err)
 	}
 
-	theirsFiles, err := collectFiles(theirsDir)
+	theirsFiles, err := vfs.Files(theirs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to collect theirs files: %w", err)
 	}
@@ -61,15 +75,14 @@ func ThreeWayMerge(baseDir, oursDir, theirsDir string) (*MergeResult, error) {
 	}
 
 	result := &MergeResult{}
+	m := &merger{base: base, ours: ours, theirs: theirs, opts: opts}
 
 	for relPath := range allFiles {
 		_, inBase := baseFiles[relPath]
 		_, inTheirs := theirsFiles[relPath]
+		oursExists := vfs.Exists(ours, relPath)
 
-		oursPath := filepath.Join(oursDir, relPath)
-		oursExists := fileExists(oursPath)
-
-		fileResult, err := mergeOneFile(baseDir, oursDir, theirsDir, relPath, inBase, oursExists, inTheirs)
+		fileResult, err := m.mergeOneFile(relPath, inBase, oursExists, inTheirs)
 		if err != nil {
 			return nil, fmt.Errorf("failed to merge %s: %w", relPath, err)
 		}
@@ -85,35 +98,58 @@ func ThreeWayMerge(baseDir, oursDir, theirsDir string) (*MergeResult, error) {
 	return result, nil
 }
 
+// merger reads the three sides of a merge.
+type merger struct {
+	base, ours, theirs billy.Filesystem
+	opts               *config.TemplateOptions
+}
+
+// side is a file on one side of a merge.
+type side struct {
+	fs   billy.Filesystem
+	path string
+}
+
 // mergeOneFile determines and executes the merge strategy for a single file.
-func mergeOneFile(baseDir, oursDir, theirsDir, relPath string, inBase, oursExists, inTheirs bool) (*MergeFileResult, error) {
-	basePath := filepath.Join(baseDir, relPath)
-	oursPath := filepath.Join(oursDir, relPath)
-	theirsPath := filepath.Join(theirsDir, relPath)
+func (m *merger) mergeOneFile(relPath string, inBase, oursExists, inTheirs bool) (*MergeFileResult, error) {
+	base := side{m.base, relPath}
+	ours := side{m.ours, relPath}
+	theirs := side{m.theirs, relPath}
 
 	switch {
 	case inBase && oursExists && inTheirs:
 		// Normal case: file exists in all three — 3-way merge
-		baseContent, _ := os.ReadFile(basePath)
-		theirsContent, _ := os.ReadFile(theirsPath)
+		mode := mergedMode(base, ours, theirs)
 
-		// If template didn't change, nothing to do
-		if bytes.Equal(baseContent, theirsContent) {
+		// If template didn't change, nothing to do unless it changed the mode
+		// or an eol pattern forces other line endings on the project's file
+		if sameContent(base, theirs) {
+			if mode != 0 || m.forcesLineEndings(ours) {
+				return &MergeFileResult{RelPath: relPath, Status: MergeClean, Mode: mode}, nil
+			}
 			return &MergeFileResult{RelPath: relPath, Status: MergeUnchanged}, nil
 		}
 
-		oursContent, _ := os.ReadFile(oursPath)
-
 		// If user hasn't modified, just take theirs
-		if bytes.Equal(baseContent, oursContent) {
+		if sameContent(base, ours) {
 			return &MergeFileResult{
 				RelPath: relPath,
 				Status:  MergeClean,
+				Mode:    mode,
+			}, nil
+		}
+
+		// Both changed — binary files and symlinks cannot be merged line by line
+		if !lineMergeable(base, ours, theirs) {
+			return &MergeFileResult{
+				RelPath:      relPath,
+				Status:       MergeConflict,
+				ConflictPath: relPath + ".sygkro-conflict",
 			}, nil
 		}
 
 		// Both changed — run git merge-file
-		_, hasConflict, err := mergeFile(basePath, oursPath, theirsPath)
+		_, hasConflict, err := m.mergeSides(&base, ours, theirs)
 		if err != nil {
 			return nil, err
 		}
@@ -129,6 +165,7 @@ func mergeOneFile(baseDir, oursDir, theirsDir, relPath string, inBase, oursExist
 		return &MergeFileResult{
 			RelPath: relPath,
 			Status:  MergeClean,
+			Mode:    mode,
 		}, nil
 
 	case inBase && oursExists && !inTheirs:
@@ -137,14 +174,12 @@ func mergeOneFile(baseDir, oursDir, theirsDir, relPath string, inBase, oursExist
 
 	case inBase && !oursExists && inTheirs:
 		// User deleted the file
-		baseContent, _ := os.ReadFile(basePath)
-		theirsContent, _ := os.ReadFile(theirsPath)
-		if bytes.Equal(baseContent, theirsContent) {
+		if sameContent(base, theirs) {
 			// Template didn't change — respect user's deletion
 			return &MergeFileResult{RelPath: relPath, Status: MergeUnchanged}, nil
 		}
 		// Template changed — treat as new file
-		return &MergeFileResult{RelPath: relPath, Status: MergeNewFile}, nil
+		return &MergeFileResult{RelPath: relPath, Status: MergeNewFile, Mode: vfs.FileMode(m.theirs, relPath)}, nil
 
 	case inBase && !oursExists && !inTheirs:
 		// Both deleted — nothing to do
@@ -152,14 +187,19 @@ func mergeOneFile(baseDir, oursDir, theirsDir, relPath string, inBase, oursExist
 
 	case !inBase && oursExists && inTheirs:
 		// File exists in project and new template but not in old template
-		oursContent, _ := os.ReadFile(oursPath)
-		theirsContent, _ := os.ReadFile(theirsPath)
-		if bytes.Equal(oursContent, theirsContent) {
+		if sameContent(ours, theirs) {
 			return &MergeFileResult{RelPath: relPath, Status: MergeUnchanged}, nil
 		}
 		// Different contents, no common ancestor — conflict
+		if !lineMergeable(ours, theirs) {
+			return &MergeFileResult{
+				RelPath:      relPath,
+				Status:       MergeConflict,
+				ConflictPath: relPath + ".sygkro-conflict",
+			}, nil
+		}
 		// Use empty base for merge-file
-		_, hasConflict, err := mergeFileWithEmptyBase(oursPath, theirsPath)
+		_, hasConflict, err := m.mergeSides(nil, ours, theirs)
 		if err != nil {
 			return nil, err
 		}
@@ -174,108 +214,77 @@ func mergeOneFile(baseDir, oursDir, theirsDir, relPath string, inBase, oursExist
 
 	case !inBase && !oursExists && inTheirs:
 		// New file from template — add to project
-		return &MergeFileResult{RelPath: relPath, Status: MergeNewFile}, nil
+		return &MergeFileResult{RelPath: relPath, Status: MergeNewFile, Mode: vfs.FileMode(m.theirs, relPath)}, nil
 
 	default:
 		return &MergeFileResult{RelPath: relPath, Status: MergeUnchanged}, nil
 	}
 }
 
-// mergeFile runs git merge-file on three files and returns the merged content.
-// Returns (mergedContent, hasConflict, error).
-func mergeFile(basePath, oursPath, theirsPath string) ([]byte, bool, error) {
-	// git merge-file modifies the first file in-place, so we work with temp copies
-	tmpDir, err := os.MkdirTemp("", "sygkro-merge-*")
-	if err != nil {
-		return nil, false, fmt.Errorf("failed to create temp dir: %w", err)
-	}
-	defer os.RemoveAll(tmpDir)
-
-	oursCopy := filepath.Join(tmpDir, "ours")
-	baseCopy := filepath.Join(tmpDir, "base")
-	theirsCopy := filepath.Join(tmpDir, "theirs")
-
-	oursContent, err := os.ReadFile(oursPath)
+// mergeSides merges the files of ours and theirs with git merge-file, with
+// an empty base when base is nil. The files are merged with "\n" line endings
+// and no byte order mark, and the result gets back those of ours, or the line
+// endings of the eol pattern matching it.
+func (m *merger) mergeSides(base *side, ours, theirs side) ([]byte, bool, error) {
+	oursContent, err := vfs.ReadFile(ours.fs, ours.path)
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to read ours: %w", err)
 	}
-	baseContent, err := os.ReadFile(basePath)
-	if err != nil {
-		return nil, false, fmt.Errorf("failed to read base: %w", err)
+	var baseContent []byte
+	if base != nil {
+		baseContent, err = vfs.ReadFile(base.fs, base.path)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read base: %w", err)
+		}
 	}
-	theirsContent, err := os.ReadFile(theirsPath)
+	theirsContent, err := vfs.ReadFile(theirs.fs, theirs.path)
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to read theirs: %w", err)
 	}
-
-	if err := os.WriteFile(oursCopy, oursContent, 0644); err != nil {
-		return nil, false, err
-	}
-	if err := os.WriteFile(baseCopy, baseContent, 0644); err != nil {
-		return nil, false, err
-	}
-	if err := os.WriteFile(theirsCopy, theirsContent, 0644); err != nil {
+	merged, hasConflict, err := mergeFile(engine.Normalize(baseContent), engine.Normalize(oursContent), engine.Normalize(theirsContent))
+	if err != nil {
 		return nil, false, err
 	}
+	encoding := engine.EncodingFor(m.opts, ours.path, engine.DetectEncoding(oursContent))
+	return encoding.Apply(merged), hasConflict, nil
+}
 
-	cmd := exec.Command("git", "merge-file", "-p", "--diff3",
-		"-L", "project",
-		"-L", "base",
-		"-L", "template",
-		oursCopy, baseCopy, theirsCopy,
-	)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err = cmd.Run()
-
-	// git merge-file exit codes:
-	// 0 = clean merge
-	// >0 = number of conflicts (but still produces output)
-	// <0 = error
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			if exitErr.ExitCode() > 0 {
-				// Conflicts detected, output still valid
-				return stdout.Bytes(), true, nil
-			}
-		}
-		return nil, false, fmt.Errorf("git merge-file failed: %v: %s", err, stderr.String())
+// forcesLineEndings reports whether an eol pattern gives the text file of
+// ours other line endings than it has.
+func (m *merger) forcesLineEndings(ours side) bool {
+	if vfs.IsSymlink(ours.fs, ours.path) {
+		return false
 	}
-
-	return stdout.Bytes(), false, nil
+	content, err := vfs.ReadFile(ours.fs, ours.path)
+	if err != nil || engine.IsBinary(content) {
+		return false
+	}
+	encoding := engine.DetectEncoding(content)
+	return engine.EncodingFor(m.opts, ours.path, encoding) != encoding
 }
 
-// mergeFileWithEmptyBase runs a merge with an empty base (no common ancestor).
-func mergeFileWithEmptyBase(oursPath, theirsPath string) ([]byte, bool, error) {
+// mergeFile runs git merge-file on three versions of a file and returns the
+// merged content. git merge-file only reads files on disk, so the versions
+// are written to a temporary directory.
+// Returns (mergedContent, hasConflict, error).
+func mergeFile(baseContent, oursContent, theirsContent []byte) ([]byte, bool, error) {
+	// git merge-file modifies the first file in-place, so we work with temp copies
 	tmpDir, err := os.MkdirTemp("", "sygkro-merge-*")
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to create temp dir: %w", err)
 	}
 	defer os.RemoveAll(tmpDir)
 
-	emptyBase := filepath.Join(tmpDir, "base")
-	if err := os.WriteFile(emptyBase, []byte{}, 0644); err != nil {
-		return nil, false, err
-	}
-
 	oursCopy := filepath.Join(tmpDir, "ours")
+	baseCopy := filepath.Join(tmpDir, "base")
 	theirsCopy := filepath.Join(tmpDir, "theirs")
 
-	oursContent, err := os.ReadFile(oursPath)
-	if err != nil {
-		return nil, false, fmt.Errorf("failed to read ours: %w", err)
-	}
-	theirsContent, err := os.ReadFile(theirsPath)
-	if err != nil {
-		return nil, false, fmt.Errorf("failed to read theirs: %w", err)
-	}
-
 	if err := os.WriteFile(oursCopy, oursContent, 0644); err != nil {
 		return nil, false, err
 	}
+	if err := os.WriteFile(baseCopy, baseContent, 0644); err != nil {
+		return nil, false, err
+	}
 	if err := os.WriteFile(theirsCopy, theirsContent, 0644); err != nil {
 		return nil, false, err
 	}
@@ -284,7 +293,7 @@ func mergeFileWithEmptyBase(oursPath, theirsPath string) ([]byte, bool, error) {
 		"-L", "project",
 		"-L", "base",
 		"-L", "template",
-		oursCopy, emptyBase, theirsCopy,
+		oursCopy, baseCopy, theirsCopy,
 	)
 
 	var stdout, stderr bytes.Buffer
@@ -292,9 +301,15 @@ func mergeFileWithEmptyBase(oursPath, theirsPath string) ([]byte, bool, error) {
 	cmd.Stderr = &stderr
 
 	err = cmd.Run()
+
+	// git merge-file exit codes:
+	// 0 = clean merge
+	// >0 = number of conflicts (but still produces output)
+	// <0 = error
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			if exitErr.ExitCode() > 0 {
+				// Conflicts detected, output still valid
 				return stdout.Bytes(), true, nil
 			}
 		}
@@ -306,79 +321,86 @@ func mergeFileWithEmptyBase(oursPath, theirsPath string) ([]byte, bool, error) {
 
 // ApplyMerge applies the merge result to the project directory.
 // For clean merges, the project file is overwritten with the merged content.
-// For conflicts, the original file is kept and a .sygkro-conflict file is created.
+// For conflicts, the original file is kept and a .sygkro-conflict file is created;
+// for binary files it holds the template's version, as they cannot be merged.
 // For new files, the file is created from the template.
 // For deleted files, no action is taken (only reported).
 func ApplyMerge(projectDir, baseDir, theirsDir string, result *MergeResult) error {
+	return ApplyMergeFS(vfs.OS(projectDir), vfs.OS(baseDir), vfs.OS(theirsDir), result, nil)
+}
+
+// ApplyMergeFS is ApplyMerge reading the rendered templates from, and
+// writing the project to, filesystems. opts are those passed to
+// ThreeWayMergeFS.
+func ApplyMergeFS(project, base, theirs billy.Filesystem, result *MergeResult, opts *config.TemplateOptions) error {
+	m := &merger{base: base, ours: project, theirs: theirs, opts: opts}
 	for _, f := range result.Files {
-		projectPath := filepath.Join(projectDir, f.RelPath)
+		baseSide := side{base, f.RelPath}
+		oursSide := side{project, f.RelPath}
+		theirsSide := side{theirs, f.RelPath}
 
 		switch f.Status {
 		case MergeClean:
-			basePath := filepath.Join(baseDir, f.RelPath)
-			oursPath := projectPath
-			theirsPath := filepath.Join(theirsDir, f.RelPath)
-
-			var merged []byte
-			var err error
+			// Keep the project's permissions unless the template changed them
+			mode := f.Mode
+			if mode == 0 {
+				mode = vfs.FileMode(project, f.RelPath)
+			}
 
-			if fileExists(basePath) {
-				merged, _, err = mergeFile(basePath, oursPath, theirsPath)
-			} else {
-				// No base — but was determined clean (identical files)
-				merged, err = os.ReadFile(theirsPath)
+			if !vfs.Exists(base, f.RelPath) || !lineMergeable(baseSide, oursSide, theirsSide) {
+				// No base — but was determined clean (identical files).
+				// A clean binary file or symlink is one the project did not change.
+				if err := vfs.CopyFile(theirs, f.RelPath, project, f.RelPath, mode); err != nil {
+					return fmt.Errorf("failed to write merged file %s: %w", f.RelPath, err)
+				}
+				continue
 			}
+
+			merged, _, err := m.mergeSides(&baseSide, oursSide, theirsSide)
 			if err != nil {
 				return fmt.Errorf("failed to merge %s: %w", f.RelPath, err)
 			}
-
-			// Preserve original file permissions
-			info, err := os.Stat(oursPath)
-			mode := os.FileMode(0644)
-			if err == nil {
-				mode = info.Mode()
-			}
-
-			if err := os.WriteFile(projectPath, merged, mode); err != nil {
+			if err := vfs.WriteFile(project, f.RelPath, merged, mode); err != nil {
 				return fmt.Errorf("failed to write merged file %s: %w", f.RelPath, err)
 			}
 
 		case MergeConflict:
-			basePath := filepath.Join(baseDir, f.RelPath)
-			oursPath := projectPath
-			theirsPath := filepath.Join(theirsDir, f.RelPath)
+			if err := project.MkdirAll(filepath.Dir(f.ConflictPath), 0755); err != nil {
+				return fmt.Errorf("failed to create directory for conflict file: %w", err)
+			}
+
+			if !lineMergeable(baseSide, oursSide, theirsSide) {
+				// The conflict file holds the template's version of a binary file or symlink
+				if err := vfs.CopyFile(theirs, f.RelPath, project, f.ConflictPath, 0644); err != nil {
+					return fmt.Errorf("failed to write conflict file %s: %w", f.ConflictPath, err)
+				}
+				continue
+			}
 
 			var merged []byte
 			var err error
-
-			if fileExists(basePath) {
-				merged, _, err = mergeFile(basePath, oursPath, theirsPath)
+			if vfs.Exists(base, f.RelPath) {
+				merged, _, err = m.mergeSides(&baseSide, oursSide, theirsSide)
 			} else {
-				merged, _, err = mergeFileWithEmptyBase(oursPath, theirsPath)
+				merged, _, err = m.mergeSides(nil, oursSide, theirsSide)
 			}
 			if err != nil {
 				return fmt.Errorf("failed to merge %s: %w", f.RelPath, err)
 			}
-
-			conflictPath := filepath.Join(projectDir, f.ConflictPath)
-			if err := os.MkdirAll(filepath.Dir(conflictPath), 0755); err != nil {
-				return fmt.Errorf("failed to create directory for conflict file: %w", err)
-			}
-			if err := os.WriteFile(conflictPath, merged, 0644); err != nil {
+			if err := vfs.WriteFile(project, f.ConflictPath, merged, 0644); err != nil {
 				return fmt.Errorf("failed to write conflict file %s: %w", f.ConflictPath, err)
 			}
 
 		case MergeNewFile:
-			theirsPath := filepath.Join(theirsDir, f.RelPath)
-			content, err := os.ReadFile(theirsPath)
-			if err != nil {
-				return fmt.Errorf("failed to read new template file %s: %w", f.RelPath, err)
+			mode := f.Mode
+			if mode == 0 {
+				mode = 0644
 			}
 
-			if err := os.MkdirAll(filepath.Dir(projectPath), 0755); err != nil {
+			if err := project.MkdirAll(filepath.Dir(f.RelPath), 0755); err != nil {
 				return fmt.Errorf("failed to create directory for new file: %w", err)
 			}
-			if err := os.WriteFile(projectPath, content, 0644); err != nil {
+			if err := vfs.CopyFile(theirs, f.RelPath, project, f.RelPath, mode); err != nil {
 				return fmt.Errorf("failed to write new file %s: %w", f.RelPath, err)
 			}
 
@@ -393,38 +415,45 @@ func ApplyMerge(projectDir, baseDir, theirsDir string, result *MergeResult) erro
 	return nil
 }
 
-// collectFiles walks a directory and returns a set of relative file paths.
-func collectFiles(dir string) (map[string]bool, error) {
-	files := make(map[string]bool)
-	if dir == "" {
-		return files, nil
-	}
-
-	// Check if directory exists
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return files, nil
-	}
-
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			return nil
+// lineMergeable reports whether the files that exist on the sides can be
+// merged line by line: none of them is a symlink or holds binary content.
+func lineMergeable(sides ...side) bool {
+	for _, s := range sides {
+		if vfs.IsSymlink(s.fs, s.path) {
+			return false
 		}
-		relPath, err := filepath.Rel(dir, path)
-		if err != nil {
-			return err
+		content, err := vfs.ReadFile(s.fs, s.path)
+		if err == nil && engine.IsBinary(content) {
+			return false
 		}
-		files[relPath] = true
-		return nil
-	})
+	}
+	return true
+}
 
-	return files, err
+// sameContent reports whether two files have the same content, or are
+// symlinks to the same target. Text files that differ only in their line
+// endings or byte order mark have the same content.
+func sameContent(a, b side) bool {
+	symlink := vfs.IsSymlink(a.fs, a.path)
+	if symlink != vfs.IsSymlink(b.fs, b.path) {
+		return false
+	}
+	contentA, _ := vfs.ReadContent(a.fs, a.path)
+	contentB, _ := vfs.ReadContent(b.fs, b.path)
+	if !symlink && !engine.IsBinary(contentA) && !engine.IsBinary(contentB) {
+		return bytes.Equal(engine.Normalize(contentA), engine.Normalize(contentB))
+	}
+	return bytes.Equal(contentA, contentB)
 }
 
-// fileExists checks if a file exists at the given path.
-func fileExists(path string) bool {
-	info, err := os.Stat(path)
-	return err == nil && !info.IsDir()
+// mergedMode returns the permissions of theirs when the template changed
+// them and the project did not, and 0 otherwise. Symlinks have no mode.
+func mergedMode(base, ours, theirs side) os.FileMode {
+	baseMode := vfs.FileMode(base.fs, base.path)
+	oursMode := vfs.FileMode(ours.fs, ours.path)
+	theirsMode := vfs.FileMode(theirs.fs, theirs.path)
+	if baseMode == 0 || theirsMode == 0 || baseMode == theirsMode || oursMode != baseMode {
+		return 0
+	}
+	return theirsMode
 }
@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+	"github.com/faradayfan/sygkro/internal/vfs"
 )
 
 // setupMergeDir creates a temp directory and writes the given files into it.
@@ -585,20 +588,10 @@ func TestThreeWayMerge_MultipleFiles_MixedOutcomes(t *testing.T) {
 // --- mergeFile unit tests ---
 
 func TestMergeFile_CleanMerge(t *testing.T) {
-	baseDir := setupMergeDir(t, map[string]string{
-		"file": "line1\nline2\nline3\nline4\nline5\nline6\nline7\n",
-	})
-	oursDir := setupMergeDir(t, map[string]string{
-		"file": "line1\nuser\nline3\nline4\nline5\nline6\nline7\n",
-	})
-	theirsDir := setupMergeDir(t, map[string]string{
-		"file": "line1\nline2\nline3\nline4\nline5\nline6\ntemplate\n",
-	})
-
 	merged, hasConflict, err := mergeFile(
-		filepath.Join(baseDir, "file"),
-		filepath.Join(oursDir, "file"),
-		filepath.Join(theirsDir, "file"),
+		[]byte("line1\nline2\nline3\nline4\nline5\nline6\nline7\n"),
+		[]byte("line1\nuser\nline3\nline4\nline5\nline6\nline7\n"),
+		[]byte("line1\nline2\nline3\nline4\nline5\nline6\ntemplate\n"),
 	)
 	if err != nil {
 		t.Fatalf("mergeFile failed: %v", err)
@@ -615,20 +608,10 @@ func TestMergeFile_CleanMerge(t *testing.T) {
 }
 
 func TestMergeFile_ConflictMerge(t *testing.T) {
-	baseDir := setupMergeDir(t, map[string]string{
-		"file": "line1\nline2\nline3\n",
-	})
-	oursDir := setupMergeDir(t, map[string]string{
-		"file": "line1\nuser\nline3\n",
-	})
-	theirsDir := setupMergeDir(t, map[string]string{
-		"file": "line1\ntemplate\nline3\n",
-	})
-
 	merged, hasConflict, err := mergeFile(
-		filepath.Join(baseDir, "file"),
-		filepath.Join(oursDir, "file"),
-		filepath.Join(theirsDir, "file"),
+		[]byte("line1\nline2\nline3\n"),
+		[]byte("line1\nuser\nline3\n"),
+		[]byte("line1\ntemplate\nline3\n"),
 	)
 	if err != nil {
 		t.Fatalf("mergeFile failed: %v", err)
@@ -642,19 +625,13 @@ func TestMergeFile_ConflictMerge(t *testing.T) {
 }
 
 func TestMergeFile_EmptyBase(t *testing.T) {
-	oursDir := setupMergeDir(t, map[string]string{
-		"file": "user content\n",
-	})
-	theirsDir := setupMergeDir(t, map[string]string{
-		"file": "template content\n",
-	})
-
-	merged, hasConflict, err := mergeFileWithEmptyBase(
-		filepath.Join(oursDir, "file"),
-		filepath.Join(theirsDir, "file"),
+	merged, hasConflict, err := mergeFile(
+		nil,
+		[]byte("user content\n"),
+		[]byte("template content\n"),
 	)
 	if err != nil {
-		t.Fatalf("mergeFileWithEmptyBase failed: %v", err)
+		t.Fatalf("mergeFile failed: %v", err)
 	}
 	// With empty base and different content, expect conflict
 	if !hasConflict {
@@ -800,3 +777,360 @@ func TestApplyMerge_NewFileInSubdirectory(t *testing.T) {
 		t.Errorf("content = %q, want %q", content, "content\n")
 	}
 }
+
+// --- Binary files ---
+
+func TestThreeWayMerge_BinaryBothChanged_Conflict(t *testing.T) {
+	base := setupMergeDir(t, map[string]string{"logo.png": "\x89PNG\x00base"})
+	ours := setupMergeDir(t, map[string]string{"logo.png": "\x89PNG\x00ours"})
+	theirs := setupMergeDir(t, map[string]string{"logo.png": "\x89PNG\x00theirs"})
+
+	result, err := ThreeWayMerge(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("ThreeWayMerge failed: %v", err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Status != MergeConflict {
+		t.Fatalf("expected a conflict, got %+v", result.Files)
+	}
+
+	if err := ApplyMerge(ours, base, theirs, result); err != nil {
+		t.Fatalf("ApplyMerge failed: %v", err)
+	}
+	if got := readFileContent(t, filepath.Join(ours, "logo.png")); got != "\x89PNG\x00ours" {
+		t.Errorf("project file = %q, want it unchanged", got)
+	}
+	if got := readFileContent(t, filepath.Join(ours, "logo.png.sygkro-conflict")); got != "\x89PNG\x00theirs" {
+		t.Errorf("conflict file = %q, want the template's version", got)
+	}
+}
+
+func TestThreeWayMerge_BinaryTemplateOnlyChange_Replaced(t *testing.T) {
+	base := setupMergeDir(t, map[string]string{"logo.png": "\x89PNG\x00base"})
+	ours := setupMergeDir(t, map[string]string{"logo.png": "\x89PNG\x00base"})
+	theirs := setupMergeDir(t, map[string]string{"logo.png": "\x89PNG\x00theirs"})
+
+	result, err := ThreeWayMerge(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("ThreeWayMerge failed: %v", err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Status != MergeClean {
+		t.Fatalf("expected a clean merge, got %+v", result.Files)
+	}
+
+	if err := ApplyMerge(ours, base, theirs, result); err != nil {
+		t.Fatalf("ApplyMerge failed: %v", err)
+	}
+	if got := readFileContent(t, filepath.Join(ours, "logo.png")); got != "\x89PNG\x00theirs" {
+		t.Errorf("project file = %q, want the template's version", got)
+	}
+}
+
+func TestThreeWayMerge_BinaryNoBase_Conflict(t *testing.T) {
+	base := t.TempDir()
+	ours := setupMergeDir(t, map[string]string{"font.ttf": "\x00\x01ours"})
+	theirs := setupMergeDir(t, map[string]string{"font.ttf": "\x00\x01theirs"})
+
+	result, err := ThreeWayMerge(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("ThreeWayMerge failed: %v", err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Status != MergeConflict {
+		t.Errorf("expected a conflict, got %+v", result.Files)
+	}
+}
+
+// --- Modes and symlinks ---
+
+// fileModeOf returns the permissions of the file at path, failing the test on error.
+func fileModeOf(t *testing.T, path string) os.FileMode {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat %s: %v", path, err)
+	}
+	return info.Mode().Perm()
+}
+
+func TestThreeWayMerge_TemplateOnlyModeChange(t *testing.T) {
+	base := setupMergeDir(t, map[string]string{"run.sh": "echo hi\n"})
+	ours := setupMergeDir(t, map[string]string{"run.sh": "echo hi\n# local\n"})
+	theirs := setupMergeDir(t, map[string]string{"run.sh": "echo hi\n"})
+	if err := os.Chmod(filepath.Join(theirs, "run.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ThreeWayMerge(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("ThreeWayMerge failed: %v", err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Status != MergeClean || result.Files[0].Mode != 0755 {
+		t.Fatalf("expected a clean merge to mode 0755, got %+v", result.Files)
+	}
+
+	if err := ApplyMerge(ours, base, theirs, result); err != nil {
+		t.Fatalf("ApplyMerge failed: %v", err)
+	}
+	path := filepath.Join(ours, "run.sh")
+	if got := fileModeOf(t, path); got != 0755 {
+		t.Errorf("mode = %04o, want 0755", got)
+	}
+	if got := readFileContent(t, path); got != "echo hi\n# local\n" {
+		t.Errorf("content = %q, want the project's content", got)
+	}
+}
+
+func TestThreeWayMerge_ProjectModeKept(t *testing.T) {
+	base := setupMergeDir(t, map[string]string{"run.sh": "echo hi\n"})
+	ours := setupMergeDir(t, map[string]string{"run.sh": "echo hi\n"})
+	theirs := setupMergeDir(t, map[string]string{"run.sh": "echo hello\n"})
+	if err := os.Chmod(filepath.Join(ours, "run.sh"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ThreeWayMerge(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("ThreeWayMerge failed: %v", err)
+	}
+	if err := ApplyMerge(ours, base, theirs, result); err != nil {
+		t.Fatalf("ApplyMerge failed: %v", err)
+	}
+	if got := fileModeOf(t, filepath.Join(ours, "run.sh")); got != 0700 {
+		t.Errorf("mode = %04o, want the project's 0700", got)
+	}
+}
+
+func TestApplyMerge_NewExecutableFile(t *testing.T) {
+	base := t.TempDir()
+	ours := t.TempDir()
+	theirs := setupMergeDir(t, map[string]string{filepath.Join("scripts", "build.sh"): "make\n"})
+	if err := os.Chmod(filepath.Join(theirs, "scripts", "build.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ThreeWayMerge(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("ThreeWayMerge failed: %v", err)
+	}
+	if err := ApplyMerge(ours, base, theirs, result); err != nil {
+		t.Fatalf("ApplyMerge failed: %v", err)
+	}
+	if got := fileModeOf(t, filepath.Join(ours, "scripts", "build.sh")); got != 0755 {
+		t.Errorf("mode = %04o, want 0755", got)
+	}
+}
+
+func TestThreeWayMerge_Symlinks(t *testing.T) {
+	base := setupMergeDir(t, map[string]string{"a.md": "a\n", "b.md": "b\n"})
+	ours := setupMergeDir(t, map[string]string{"a.md": "a\n", "b.md": "b\n"})
+	theirs := setupMergeDir(t, map[string]string{"a.md": "a\n", "b.md": "b\n"})
+	links := []struct{ dir, name, target string }{
+		{base, "README.md", "a.md"},
+		{ours, "README.md", "a.md"},
+		{theirs, "README.md", "b.md"},
+		{theirs, "CHANGELOG.md", "docs/changes.md"}, // dangling
+	}
+	for _, link := range links {
+		if err := os.Symlink(link.target, filepath.Join(link.dir, link.name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := ThreeWayMerge(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("ThreeWayMerge failed: %v", err)
+	}
+	if len(result.Files) != 2 || result.HasConflict {
+		t.Fatalf("expected two clean results, got %+v", result.Files)
+	}
+	if err := ApplyMerge(ours, base, theirs, result); err != nil {
+		t.Fatalf("ApplyMerge failed: %v", err)
+	}
+
+	for name, want := range map[string]string{"README.md": "b.md", "CHANGELOG.md": "docs/changes.md"} {
+		target, err := os.Readlink(filepath.Join(ours, name))
+		if err != nil {
+			t.Fatalf("%s is not a symlink: %v", name, err)
+		}
+		if target != want {
+			t.Errorf("%s -> %s, want %s", name, target, want)
+		}
+	}
+}
+
+func TestThreeWayMerge_SymlinkBothChanged_Conflict(t *testing.T) {
+	base := t.TempDir()
+	ours := t.TempDir()
+	theirs := t.TempDir()
+	for dir, target := range map[string]string{base: "a.md", theirs: "b.md"} {
+		if err := os.Symlink(target, filepath.Join(dir, "README.md")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The project replaced the link with a regular file
+	if err := os.WriteFile(filepath.Join(ours, "README.md"), []byte("own readme\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ThreeWayMerge(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("ThreeWayMerge failed: %v", err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Status != MergeConflict {
+		t.Fatalf("expected a conflict, got %+v", result.Files)
+	}
+	if err := ApplyMerge(ours, base, theirs, result); err != nil {
+		t.Fatalf("ApplyMerge failed: %v", err)
+	}
+	if got := readFileContent(t, filepath.Join(ours, "README.md")); got != "own readme\n" {
+		t.Errorf("project file = %q, want it unchanged", got)
+	}
+	if target, err := os.Readlink(filepath.Join(ours, "README.md.sygkro-conflict")); err != nil || target != "b.md" {
+		t.Errorf("conflict file should link to b.md, got %q (%v)", target, err)
+	}
+}
+
+func TestThreeWayMergeFS_InMemoryRenders(t *testing.T) {
+	base := vfs.Memory()
+	theirs := vfs.Memory()
+	for path, content := range map[string]string{
+		"file.txt":       "line1\nline2\nline3\nline4\nline5\nline6\nline7\n",
+		"bin/run.sh":     "echo run\n",
+		"docs/readme.md": "docs\n",
+	} {
+		if err := vfs.WriteFile(base, path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for path, content := range map[string]string{
+		"file.txt":       "line1\nline2\nline3\nline4\nline5\nline6\ntemplate\n",
+		"docs/readme.md": "docs\n",
+		"docs/new.md":    "new\n",
+	} {
+		if err := vfs.WriteFile(theirs, path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := vfs.WriteFile(theirs, "bin/run.sh", []byte("echo run\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := vfs.WriteSymlink(theirs, "README.md", "docs/readme.md"); err != nil {
+		t.Fatal(err)
+	}
+
+	ours := setupMergeDir(t, map[string]string{
+		"file.txt":       "line1\nuser\nline3\nline4\nline5\nline6\nline7\n",
+		"bin/run.sh":     "echo run\n",
+		"docs/readme.md": "docs\n",
+	})
+	project := vfs.OS(ours)
+
+	result, err := ThreeWayMergeFS(base, project, theirs, nil)
+	if err != nil {
+		t.Fatalf("ThreeWayMergeFS failed: %v", err)
+	}
+	if result.HasConflict {
+		t.Fatalf("expected no conflicts, got %+v", result.Files)
+	}
+	if err := ApplyMergeFS(project, base, theirs, result, nil); err != nil {
+		t.Fatalf("ApplyMergeFS failed: %v", err)
+	}
+
+	if got, want := readFileContent(t, filepath.Join(ours, "file.txt")), "line1\nuser\nline3\nline4\nline5\nline6\ntemplate\n"; got != want {
+		t.Errorf("file.txt = %q, want %q", got, want)
+	}
+	if got := readFileContent(t, filepath.Join(ours, "docs", "new.md")); got != "new\n" {
+		t.Errorf("docs/new.md = %q", got)
+	}
+	if mode := fileModeOf(t, filepath.Join(ours, "bin", "run.sh")); mode != 0755 {
+		t.Errorf("bin/run.sh mode = %04o, want 0755", mode)
+	}
+	if target, err := os.Readlink(filepath.Join(ours, "README.md")); err != nil || target != "docs/readme.md" {
+		t.Errorf("README.md -> %q (%v), want docs/readme.md", target, err)
+	}
+}
+
+func TestThreeWayMerge_LineEndingsOnlyDiffer_Unchanged(t *testing.T) {
+	base := setupMergeDir(t, map[string]string{"file.txt": "line1\r\nline2\r\n"})
+	ours := setupMergeDir(t, map[string]string{"file.txt": "\xef\xbb\xbfline1\nline2\n"})
+	theirs := setupMergeDir(t, map[string]string{"file.txt": "line1\nline2\n"})
+
+	result, err := ThreeWayMerge(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("ThreeWayMerge failed: %v", err)
+	}
+	if len(result.Files) != 0 {
+		t.Errorf("expected no changes, got %+v", result.Files)
+	}
+}
+
+func TestThreeWayMerge_KeepsProjectLineEndingsAndBOM(t *testing.T) {
+	base := setupMergeDir(t, map[string]string{
+		"file.txt":     "line1\nline2\nline3\nline4\nline5\nline6\nline7\n",
+		"conflict.txt": "line1\nline2\nline3\n",
+	})
+	ours := setupMergeDir(t, map[string]string{
+		"file.txt":     "\xef\xbb\xbfline1\r\nuser\r\nline3\r\nline4\r\nline5\r\nline6\r\nline7\r\n",
+		"conflict.txt": "line1\r\nuser\r\nline3\r\n",
+	})
+	theirs := setupMergeDir(t, map[string]string{
+		"file.txt":     "line1\nline2\nline3\nline4\nline5\nline6\ntemplate\n",
+		"conflict.txt": "line1\ntemplate\nline3\n",
+	})
+
+	result, err := ThreeWayMerge(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("ThreeWayMerge failed: %v", err)
+	}
+	if err := ApplyMerge(ours, base, theirs, result); err != nil {
+		t.Fatalf("ApplyMerge failed: %v", err)
+	}
+
+	want := "\xef\xbb\xbfline1\r\nuser\r\nline3\r\nline4\r\nline5\r\nline6\r\ntemplate\r\n"
+	if got := readFileContent(t, filepath.Join(ours, "file.txt")); got != want {
+		t.Errorf("file.txt = %q, want %q", got, want)
+	}
+	conflict := readFileContent(t, filepath.Join(ours, "conflict.txt.sygkro-conflict"))
+	if !strings.Contains(conflict, "<<<<<<< project\r\nuser\r\n") || strings.Contains(strings.ReplaceAll(conflict, "\r\n", ""), "\n") {
+		t.Errorf("expected a conflict file with CRLF line endings, got %q", conflict)
+	}
+}
+
+func TestThreeWayMergeFS_EOLOverridesProjectLineEndings(t *testing.T) {
+	base := setupMergeDir(t, map[string]string{
+		"run.bat":   "line1\nline2\nline3\nline4\nline5\nline6\nline7\n",
+		"setup.bat": "@echo off\n",
+		"notes.txt": "line1\nline2\nline3\nline4\nline5\nline6\nline7\n",
+	})
+	ours := setupMergeDir(t, map[string]string{
+		"run.bat":   "line1\nuser\nline3\nline4\nline5\nline6\nline7\n",
+		"setup.bat": "@echo off\n",
+		"notes.txt": "line1\nuser\nline3\nline4\nline5\nline6\nline7\n",
+	})
+	theirs := setupMergeDir(t, map[string]string{
+		"run.bat":   "line1\r\nline2\r\nline3\r\nline4\r\nline5\r\nline6\r\ntemplate\r\n",
+		"setup.bat": "@echo off\r\n",
+		"notes.txt": "line1\r\nline2\r\nline3\r\nline4\r\nline5\r\nline6\r\ntemplate\r\n",
+	})
+	opts := &config.TemplateOptions{EOL: map[string]string{"*.bat": "crlf"}}
+
+	result, err := ThreeWayMergeFS(vfs.OS(base), vfs.OS(ours), vfs.OS(theirs), opts)
+	if err != nil {
+		t.Fatalf("ThreeWayMergeFS failed: %v", err)
+	}
+	if len(result.Files) != 3 || result.HasConflict {
+		t.Fatalf("expected three clean results, got %+v", result.Files)
+	}
+	if err := ApplyMergeFS(vfs.OS(ours), vfs.OS(base), vfs.OS(theirs), result, opts); err != nil {
+		t.Fatalf("ApplyMergeFS failed: %v", err)
+	}
+
+	for path, want := range map[string]string{
+		"run.bat":   "line1\r\nuser\r\nline3\r\nline4\r\nline5\r\nline6\r\ntemplate\r\n",
+		"setup.bat": "@echo off\r\n",
+		"notes.txt": "line1\nuser\nline3\nline4\nline5\nline6\ntemplate\n",
+	} {
+		if got := readFileContent(t, filepath.Join(ours, path)); got != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package git
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/faradayfan/sygkro/internal/vfs"
+	"github.com/go-git/go-billy/v5"
+)
+
+// MoveRenamedFiles moves files within dir from the old to the new relative
+// paths in renames, so that a following ThreeWayMerge sees a renamed file as
+// the same file rather than as a deletion and an addition. A file is only
+// moved when it exists at its old path and nothing exists at its new path.
+// Directories left empty by a move are removed. It returns the old paths of
+// the files that were moved, sorted.
+func MoveRenamedFiles(dir string, renames map[string]string) ([]string, error) {
+	return MoveRenamedFilesFS(vfs.OS(dir), renames)
+}
+
+// MoveRenamedFilesFS is MoveRenamedFiles for the files of fs.
+func MoveRenamedFilesFS(fs billy.Filesystem, renames map[string]string) ([]string, error) {
+	oldPaths := make([]string, 0, len(renames))
+	for oldPath := range renames {
+		oldPaths = append(oldPaths, oldPath)
+	}
+	sort.Strings(oldPaths)
+
+	var moved []string
+	for _, oldPath := range oldPaths {
+		newPath := renames[oldPath]
+		if !vfs.Exists(fs, oldPath) {
+			continue
+		}
+		if _, err := fs.Lstat(newPath); err == nil {
+			continue
+		}
+
+		if err := fs.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+			return moved, fmt.Errorf("failed to create directory for %s: %w", newPath, err)
+		}
+		if err := vfs.Move(fs, oldPath, newPath); err != nil {
+			return moved, fmt.Errorf("failed to move %s to %s: %w", oldPath, newPath, err)
+		}
+		removeEmptyParents(fs, filepath.Dir(oldPath))
+		moved = append(moved, oldPath)
+	}
+
+	return moved, nil
+}
+
+// removeEmptyParents removes path and its parents, up to but excluding the
+// root of fs, for as long as they are empty directories.
+func removeEmptyParents(fs billy.Filesystem, path string) {
+	for path = filepath.Clean(path); path != "." && path != string(filepath.Separator); path = filepath.Dir(path) {
+		if err := fs.Remove(path); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/vfs"
+)
+
+func TestMoveRenamedFiles(t *testing.T) {
+	dir := setupMergeDir(t, map[string]string{
+		"cmd/old/main.go": "package main\n",
+		"docs/old.md":     "kept\n",
+		"docs/new.md":     "already here\n",
+	})
+
+	renames := map[string]string{
+		filepath.Join("cmd", "old", "main.go"): filepath.Join("cmd", "new", "main.go"),
+		filepath.Join("docs", "old.md"):        filepath.Join("docs", "new.md"),
+		"missing.txt":                          "other.txt",
+	}
+
+	moved, err := MoveRenamedFiles(dir, renames)
+	if err != nil {
+		t.Fatalf("MoveRenamedFiles failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(moved, []string{filepath.Join("cmd", "old", "main.go")}) {
+		t.Errorf("moved = %v", moved)
+	}
+	if got := readFileContent(t, filepath.Join(dir, "cmd", "new", "main.go")); got != "package main\n" {
+		t.Errorf("moved file content = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cmd", "old")); !os.IsNotExist(err) {
+		t.Error("expected the emptied directory to be removed")
+	}
+	if got := readFileContent(t, filepath.Join(dir, "docs", "new.md")); got != "already here\n" {
+		t.Errorf("existing target was overwritten: %q", got)
+	}
+	if got := readFileContent(t, filepath.Join(dir, "docs", "old.md")); got != "kept\n" {
+		t.Errorf("source with an existing target should stay: %q", got)
+	}
+}
+
+func TestMoveRenamedFilesFS_InMemory(t *testing.T) {
+	fs := vfs.Memory()
+	for path, content := range map[string]string{
+		"app.go":      "package app\n",
+		"app.go.tmpl": "not renamed\n",
+		"old/main.go": "package main\n",
+	} {
+		if err := vfs.WriteFile(fs, path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	moved, err := MoveRenamedFilesFS(fs, map[string]string{
+		"app.go":                        "shop.go",
+		filepath.Join("old", "main.go"): filepath.Join("new", "main.go"),
+	})
+	if err != nil {
+		t.Fatalf("MoveRenamedFilesFS failed: %v", err)
+	}
+	if len(moved) != 2 {
+		t.Errorf("moved = %v", moved)
+	}
+
+	files, err := vfs.Files(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"shop.go": true, "app.go.tmpl": true, filepath.Join("new", "main.go"): true}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+	if _, err := fs.Lstat("old"); !os.IsNotExist(err) {
+		t.Error("expected the emptied directory to be removed")
+	}
+}
@@ -3,25 +3,110 @@ package git
 import (
 	"fmt"
 	"path/filepath"
+	"sync"
 
 	"github.com/faradayfan/sygkro/internal/config"
 	"github.com/faradayfan/sygkro/internal/engine"
+	"github.com/faradayfan/sygkro/internal/vfs"
+	"github.com/go-git/go-billy/v5"
 )
 
 // RenderTemplateAtPath renders a template directory into a target directory
 // using the given inputs. It reads the template config from templateDir,
-// finds the "{{ .slug }}" subdirectory, and processes it into targetDir.
-func RenderTemplateAtPath(templateDir string, targetDir string, inputs map[string]string) error {
+// finds the "{{ .slug }}" subdirectory, and processes it into targetDir with
+// the partials of the template's _partials directory.
+func RenderTemplateAtPath(templateDir string, targetDir string, inputs map[string]any) error {
+	return RenderTemplateAtPathWith(templateDir, targetDir, inputs, RenderOptions{})
+}
+
+// RenderOptions are the command-line options of a render.
+type RenderOptions struct {
+	// Strict turns on strict mode, whatever the template's options say.
+	Strict bool
+	// Jobs is the number of files rendered at once, or 0 for one per CPU.
+	Jobs int
+	// FormatAs, when set, formats the files with the formatters of another
+	// version of the template instead of those of this one, so that a
+	// formatter the versions do not share is not mistaken for a change.
+	FormatAs *config.TemplateConfig
+}
+
+// RenderTemplateAtPathWith is RenderTemplateAtPath with the given options.
+func RenderTemplateAtPathWith(templateDir string, targetDir string, inputs map[string]any, opts RenderOptions) error {
+	return RenderTemplate(templateDir, vfs.OS(targetDir), inputs, opts)
+}
+
+// RenderTemplate is RenderTemplateAtPathWith rendering into the filesystem
+// target, such as vfs.Memory() for a render that is only merged or compared.
+func RenderTemplate(templateDir string, target billy.Filesystem, inputs map[string]any, opts RenderOptions) error {
 	templateConfig, err := config.ReadTemplateConfig(filepath.Join(templateDir, config.TemplateConfigFileName))
 	if err != nil {
 		return fmt.Errorf("failed to read template config: %w", err)
 	}
+	if opts.Strict {
+		templateConfig.SetStrict()
+	}
+	if opts.FormatAs != nil {
+		templateConfig.SetFormatters(opts.FormatAs)
+	}
 
 	slugDir := filepath.Join(templateDir, "{{ .slug }}")
 
-	if err := engine.ProcessTemplateDir(slugDir, targetDir, inputs, templateConfig.Options); err != nil {
+	if err := engine.ProcessTemplateFS(slugDir, target, templateConfig.RenderData(inputs), templateConfig.Options, opts.Jobs); err != nil {
 		return fmt.Errorf("failed to render template: %w", err)
 	}
 
 	return nil
 }
+
+// RenderConcurrently runs renders, such as those of the old and new versions
+// of a template, at the same time. It returns the error of the first render
+// that failed, in the order of renders.
+func RenderConcurrently(renders ...func() error) error {
+	errs := make([]error, len(renders))
+	var wg sync.WaitGroup
+	for i, render := range renders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = render()
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenamedPaths returns the rendered files of a template whose paths depend on
+// inputs that differ between oldInputs and newInputs, mapped from the path
+// rendered with oldInputs to the path rendered with newInputs.
+func RenamedPaths(templateDir string, oldInputs, newInputs map[string]any) (map[string]string, error) {
+	templateConfig, err := config.ReadTemplateConfig(filepath.Join(templateDir, config.TemplateConfigFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template config: %w", err)
+	}
+
+	slugDir := filepath.Join(templateDir, "{{ .slug }}")
+
+	oldPaths, err := engine.RenderPaths(slugDir, templateConfig.RenderData(oldInputs), templateConfig.Options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render paths with the old inputs: %w", err)
+	}
+	newPaths, err := engine.RenderPaths(slugDir, templateConfig.RenderData(newInputs), templateConfig.Options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render paths with the new inputs: %w", err)
+	}
+
+	renames := make(map[string]string)
+	for source, oldPath := range oldPaths {
+		if newPath, ok := newPaths[source]; ok && newPath != oldPath {
+			renames[oldPath] = newPath
+		}
+	}
+	return renames, nil
+}
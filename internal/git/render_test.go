@@ -1,16 +1,35 @@
 package git
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/faradayfan/sygkro/internal/config"
+	"github.com/faradayfan/sygkro/internal/vfs"
 )
 
+// inputSpecs builds string input specs, sorted by name, whose defaults are the given values.
+func inputSpecs(values map[string]any) config.Inputs {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	specs := make(config.Inputs, 0, len(values))
+	for _, name := range names {
+		specs = append(specs, &config.InputSpec{Name: name, Type: config.InputTypeString, Default: values[name]})
+	}
+	return specs
+}
+
 func TestRenderTemplateAtPath_Basic(t *testing.T) {
 	templateDir := t.TempDir()
-	inputs := map[string]string{
+	inputs := map[string]any{
 		"name": "my-project",
 		"slug": "my-project",
 	}
@@ -20,7 +39,7 @@ func TestRenderTemplateAtPath_Basic(t *testing.T) {
 		Name:        "test-template",
 		Description: "A test template",
 		Templating: config.TemplatingConfig{
-			Inputs: inputs,
+			Inputs: inputSpecs(inputs),
 		},
 	}
 	if err := cfg.Write(filepath.Join(templateDir, config.TemplateConfigFileName)); err != nil {
@@ -56,7 +75,7 @@ func TestRenderTemplateAtPath_Basic(t *testing.T) {
 func TestRenderTemplateAtPath_MissingConfig(t *testing.T) {
 	templateDir := t.TempDir()
 	targetDir := t.TempDir()
-	inputs := map[string]string{"name": "test"}
+	inputs := map[string]any{"name": "test"}
 
 	err := RenderTemplateAtPath(templateDir, targetDir, inputs)
 	if err == nil {
@@ -67,13 +86,13 @@ func TestRenderTemplateAtPath_MissingConfig(t *testing.T) {
 func TestRenderTemplateAtPath_MissingSlugDir(t *testing.T) {
 	templateDir := t.TempDir()
 	targetDir := t.TempDir()
-	inputs := map[string]string{"name": "test", "slug": "test"}
+	inputs := map[string]any{"name": "test", "slug": "test"}
 
 	// Create config but no slug directory
 	cfg := config.TemplateConfig{
 		Name: "test",
 		Templating: config.TemplatingConfig{
-			Inputs: inputs,
+			Inputs: inputSpecs(inputs),
 		},
 	}
 	if err := cfg.Write(filepath.Join(templateDir, config.TemplateConfigFileName)); err != nil {
@@ -85,3 +104,192 @@ func TestRenderTemplateAtPath_MissingSlugDir(t *testing.T) {
 		t.Error("expected error for missing slug directory")
 	}
 }
+
+func TestRenamedPaths(t *testing.T) {
+	templateDir := t.TempDir()
+	cfg := config.TemplateConfig{Name: "test"}
+	if err := cfg.Write(filepath.Join(templateDir, config.TemplateConfigFileName)); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	slugDir := filepath.Join(templateDir, "{{ .slug }}")
+	if err := os.MkdirAll(filepath.Join(slugDir, "cmd", "{{ .slug }}"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(slugDir, "README.md"), []byte("# {{ .name }}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(slugDir, "cmd", "{{ .slug }}", "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	renames, err := RenamedPaths(templateDir,
+		map[string]any{"name": "Old", "slug": "old"},
+		map[string]any{"name": "New", "slug": "new"},
+	)
+	if err != nil {
+		t.Fatalf("RenamedPaths failed: %v", err)
+	}
+
+	want := map[string]string{filepath.Join("cmd", "old", "main.go"): filepath.Join("cmd", "new", "main.go")}
+	if len(renames) != 1 || renames[filepath.Join("cmd", "old", "main.go")] != want[filepath.Join("cmd", "old", "main.go")] {
+		t.Errorf("renames = %v, want %v", renames, want)
+	}
+}
+
+func TestThreeWayMerge_ForeachAddsFilesForNewElements(t *testing.T) {
+	templateDir := t.TempDir()
+	cfg := config.TemplateConfig{
+		Name: "test",
+		Options: &config.TemplateOptions{Foreach: map[string]string{
+			filepath.Join("services", "{{ .item }}"): ".services",
+		}},
+	}
+	if err := cfg.Write(filepath.Join(templateDir, config.TemplateConfigFileName)); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	serviceDir := filepath.Join(templateDir, "{{ .slug }}", "services", "{{ .item }}")
+	if err := os.MkdirAll(serviceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(serviceDir, "main.go"), []byte("// {{ .item }}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	baseDir, projectDir, theirsDir := t.TempDir(), t.TempDir(), t.TempDir()
+	oldInputs := map[string]any{"slug": "shop", "services": []any{"api"}}
+	newInputs := map[string]any{"slug": "shop", "services": []any{"api", "worker"}}
+	for dir, inputs := range map[string]map[string]any{baseDir: oldInputs, projectDir: oldInputs, theirsDir: newInputs} {
+		if err := RenderTemplateAtPath(templateDir, dir, inputs); err != nil {
+			t.Fatalf("RenderTemplateAtPath failed: %v", err)
+		}
+	}
+
+	result, err := ThreeWayMerge(baseDir, projectDir, theirsDir)
+	if err != nil {
+		t.Fatalf("ThreeWayMerge failed: %v", err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Status != MergeNewFile || result.Files[0].RelPath != filepath.Join("services", "worker", "main.go") {
+		t.Errorf("expected only services/worker/main.go to be added, got %+v", result.Files)
+	}
+}
+
+func TestRenderTemplateAtPathWith_Strict(t *testing.T) {
+	templateDir := t.TempDir()
+	inputs := map[string]any{"slug": "my-project"}
+
+	cfg := config.TemplateConfig{
+		Name:       "test-template",
+		Templating: config.TemplatingConfig{Inputs: inputSpecs(inputs)},
+	}
+	if err := cfg.Write(filepath.Join(templateDir, config.TemplateConfigFileName)); err != nil {
+		t.Fatalf("failed to write template config: %v", err)
+	}
+	slugDir := filepath.Join(templateDir, "{{ .slug }}")
+	if err := os.MkdirAll(slugDir, 0755); err != nil {
+		t.Fatalf("failed to create slug dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(slugDir, "README.md"), []byte("# {{ .nmae }}\n"), 0644); err != nil {
+		t.Fatalf("failed to write template file: %v", err)
+	}
+
+	if err := RenderTemplateAtPathWith(templateDir, t.TempDir(), inputs, RenderOptions{}); err != nil {
+		t.Fatalf("expected the template's own options to apply, got %v", err)
+	}
+	err := RenderTemplateAtPathWith(templateDir, t.TempDir(), inputs, RenderOptions{Strict: true})
+	if err == nil || !strings.Contains(err.Error(), "README.md:1:6: .nmae is not defined") {
+		t.Errorf("expected the undefined reference to be reported, got %v", err)
+	}
+}
+
+func TestRenderTemplateAtPathWith_StrictWithInputTurnedOff(t *testing.T) {
+	templateDir := t.TempDir()
+	cfg := config.TemplateConfig{
+		Name: "test-template",
+		Templating: config.TemplatingConfig{Inputs: config.Inputs{
+			{Name: "slug", Type: config.InputTypeString},
+			{Name: "use_docker", Type: config.InputTypeBool},
+			{Name: "registry", Type: config.InputTypeString, When: ".use_docker"},
+			{Name: "ports", Type: config.InputTypeList, When: ".use_docker"},
+		}},
+	}
+	if err := cfg.Write(filepath.Join(templateDir, config.TemplateConfigFileName)); err != nil {
+		t.Fatalf("failed to write template config: %v", err)
+	}
+	slugDir := filepath.Join(templateDir, "{{ .slug }}")
+	if err := os.MkdirAll(slugDir, 0755); err != nil {
+		t.Fatalf("failed to create slug dir: %v", err)
+	}
+	content := "{{ if .registry }}{{ .registry }}{{ end }}{{ range .ports }}{{ . }}{{ end }}done\n"
+	if err := os.WriteFile(filepath.Join(slugDir, "a.txt"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write template file: %v", err)
+	}
+
+	// registry and ports were turned off by their when condition, so Collect left them out
+	targetDir := t.TempDir()
+	inputs := map[string]any{"slug": "my-project", "use_docker": false}
+	if err := RenderTemplateAtPathWith(templateDir, targetDir, inputs, RenderOptions{Strict: true}); err != nil {
+		t.Fatalf("expected inputs turned off by when to be defined, got %v", err)
+	}
+	if got := readFileContent(t, filepath.Join(targetDir, "a.txt")); got != "done\n" {
+		t.Errorf("a.txt = %q, want %q", got, "done\n")
+	}
+}
+
+func TestRenderTemplate_FormatAs(t *testing.T) {
+	templateDir := t.TempDir()
+	cfg := config.TemplateConfig{
+		Name:       "test-template",
+		Templating: config.TemplatingConfig{Inputs: inputSpecs(map[string]any{"slug": "my-project"})},
+		Options: &config.TemplateOptions{Formatters: map[string][]string{
+			"*.go":  {"gofmt"},
+			"*.txt": {"final_newline"},
+		}},
+	}
+	if err := cfg.Write(filepath.Join(templateDir, config.TemplateConfigFileName)); err != nil {
+		t.Fatalf("failed to write template config: %v", err)
+	}
+	slugDir := filepath.Join(templateDir, "{{ .slug }}")
+	if err := os.MkdirAll(slugDir, 0755); err != nil {
+		t.Fatalf("failed to create slug dir: %v", err)
+	}
+	for name, content := range map[string]string{"main.go": "package main\nfunc main( ) {}\n", "notes.txt": "notes"} {
+		if err := os.WriteFile(filepath.Join(slugDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write template file: %v", err)
+		}
+	}
+
+	// The new version drops gofmt and keeps final_newline
+	newConfig := &config.TemplateConfig{Options: &config.TemplateOptions{Formatters: map[string][]string{
+		"*.txt": {"final_newline"},
+	}}}
+	target := vfs.Memory()
+	opts := RenderOptions{FormatAs: newConfig}
+	if err := RenderTemplate(templateDir, target, map[string]any{"slug": "my-project"}, opts); err != nil {
+		t.Fatalf("RenderTemplate failed: %v", err)
+	}
+
+	for path, want := range map[string]string{"main.go": "package main\nfunc main( ) {}\n", "notes.txt": "notes\n"} {
+		data, err := vfs.ReadFile(target, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", path, data, want)
+		}
+	}
+}
+
+func TestRenderConcurrently(t *testing.T) {
+	var ran [3]bool
+	err := RenderConcurrently(
+		func() error { ran[0] = true; return nil },
+		func() error { ran[1] = true; return errors.New("old failed") },
+		func() error { ran[2] = true; return errors.New("new failed") },
+	)
+	if err == nil || err.Error() != "old failed" {
+		t.Errorf("RenderConcurrently = %v, want the error of the first render that failed", err)
+	}
+	if ran != [3]bool{true, true, true} {
+		t.Errorf("expected every render to run, ran %v", ran)
+	}
+}
@@ -82,10 +82,10 @@ func buildTemplateRepo(t *testing.T) (repoDir, v1sha, v2sha string) {
 		Name:        "test-template",
 		Description: "Integration test template",
 		Templating: config.TemplatingConfig{
-			Inputs: map[string]string{
+			Inputs: inputSpecs(map[string]any{
 				"name": "My App",
 				"slug": "my-app",
-			},
+			}),
 		},
 	}
 	if err := cfg.Write(filepath.Join(repoDir, config.TemplateConfigFileName)); err != nil {
@@ -128,7 +128,7 @@ func buildTemplateRepo(t *testing.T) (repoDir, v1sha, v2sha string) {
 func TestSyncIntegration_FullFlow(t *testing.T) {
 	templateRepo, v1sha, _ := buildTemplateRepo(t)
 
-	inputs := map[string]string{
+	inputs := map[string]any{
 		"name": "My App",
 		"slug": "my-app",
 	}
@@ -297,7 +297,7 @@ func TestSyncIntegration_FullFlow(t *testing.T) {
 func TestSyncIntegration_NoChanges(t *testing.T) {
 	templateRepo, v1sha, _ := buildTemplateRepo(t)
 
-	inputs := map[string]string{"name": "My App", "slug": "my-app"}
+	inputs := map[string]any{"name": "My App", "slug": "my-app"}
 
 	// Render project at v1
 	mustCheckout(t, templateRepo, v1sha)
@@ -347,7 +347,7 @@ func TestSyncIntegration_NoChanges(t *testing.T) {
 func TestSyncIntegration_FirstSync(t *testing.T) {
 	templateRepo, _, _ := buildTemplateRepo(t)
 
-	inputs := map[string]string{"name": "My App", "slug": "my-app"}
+	inputs := map[string]any{"name": "My App", "slug": "my-app"}
 
 	// Project has some pre-existing files (simulating project link)
 	projectDir := t.TempDir()
@@ -408,7 +408,7 @@ func TestSyncIntegration_FirstSync(t *testing.T) {
 func TestSyncIntegration_TemplateDeletesFile(t *testing.T) {
 	templateRepo, v1sha, _ := buildTemplateRepo(t)
 
-	inputs := map[string]string{"name": "My App", "slug": "my-app"}
+	inputs := map[string]any{"name": "My App", "slug": "my-app"}
 
 	// Render project at v1
 	mustCheckout(t, templateRepo, v1sha)
@@ -471,13 +471,17 @@ func TestSyncIntegration_TemplateDeletesFile(t *testing.T) {
 func TestSyncIntegration_ComputeTemplateDiff(t *testing.T) {
 	templateRepo, v1sha, _ := buildTemplateRepo(t)
 
-	inputs := map[string]string{"name": "My App", "slug": "my-app"}
-	syncConfig := &config.SyncConfig{Inputs: inputs}
+	inputs := map[string]any{"name": "My App", "slug": "my-app"}
 
 	// Checkout v2 (HEAD) first, then diff against v1
 	mustCheckout(t, templateRepo, "main")
+	oldTemplateDir, cleanup, err := GitWorktree(templateRepo, v1sha)
+	if err != nil {
+		t.Fatalf("GitWorktree failed: %v", err)
+	}
 
-	diff, err := ComputeTemplateDiff(templateRepo, v1sha, syncConfig)
+	diff, err := ComputeTemplateDiff(templateRepo, oldTemplateDir, inputs, inputs, 2)
+	cleanup()
 	if err != nil {
 		t.Fatalf("ComputeTemplateDiff failed: %v", err)
 	}
@@ -502,6 +506,18 @@ func TestSyncIntegration_ComputeTemplateDiff(t *testing.T) {
 
 	// Diff should NOT contain any user customizations (there are none in the template diff)
 	// This is the key difference from the old ComputeDiff behavior
+
+	// The old version was rendered from a worktree, which cleanup removes again
+	worktrees, err := runCommand(templateRepo, "git", "worktree", "list", "--porcelain")
+	if err != nil {
+		t.Fatalf("git worktree list failed: %v", err)
+	}
+	if n := strings.Count(worktrees, "worktree "); n != 1 {
+		t.Errorf("expected only the main worktree to be left, got:\n%s", worktrees)
+	}
+	if !fileExists(filepath.Join(templateRepo, "{{ .slug }}", "Makefile")) {
+		t.Error("the template repo should stay checked out at the new version")
+	}
 }
 
 func assertFileContent(t *testing.T, path, expected string) {
@@ -514,3 +530,9 @@ func assertFileContent(t *testing.T, path, expected string) {
 		t.Errorf("%s content = %q, want %q", filepath.Base(path), string(content), expected)
 	}
 }
+
+// fileExists checks if a file or symlink exists at the given path.
+func fileExists(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && !info.IsDir()
+}
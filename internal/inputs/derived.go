@@ -0,0 +1,185 @@
+package inputs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/faradayfan/sygkro/internal/config"
+	"github.com/faradayfan/sygkro/internal/engine"
+)
+
+// isTemplate reports whether a default value is a template expression that
+// must be rendered against earlier answers.
+func isTemplate(value any) bool {
+	str, ok := value.(string)
+	return ok && strings.Contains(str, "{{")
+}
+
+// dependencies returns the declared inputs referenced by an input's default
+// and by its when condition.
+func dependencies(templating *config.TemplatingConfig, spec *config.InputSpec) ([]string, error) {
+	var refs []string
+	if isTemplate(spec.Default) {
+		defaultRefs, err := engine.TemplateRefs(spec.Default.(string))
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, defaultRefs...)
+	}
+	if spec.When != "" {
+		whenRefs, err := engine.TemplateRefs(engine.Expression(spec.When))
+		if err != nil {
+			return nil, fmt.Errorf("invalid when condition: %w", err)
+		}
+		refs = append(refs, whenRefs...)
+	}
+
+	var deps []string
+	for _, ref := range refs {
+		if templating.Input(ref) != nil {
+			deps = append(deps, ref)
+		}
+	}
+	return deps, nil
+}
+
+// Order returns the input names in the order they must be resolved: every
+// input comes after the inputs its default and when condition refer to, and
+// otherwise inputs keep their declaration order. The inputs of a group stay
+// together, so a group is moved ahead of the inputs that depend on it. A
+// dependency cycle, between inputs or between groups, is an error.
+func Order(templating *config.TemplatingConfig) ([]string, error) {
+	declared := templating.AllInputs()
+	names := make([]string, 0, len(declared))
+	deps := make(map[string][]string, len(declared))
+	for _, spec := range declared {
+		inputDeps, err := dependencies(templating, spec)
+		if err != nil {
+			return nil, fmt.Errorf("input %s: %w", spec.Name, err)
+		}
+		names = append(names, spec.Name)
+		deps[spec.Name] = inputDeps
+	}
+
+	if _, err := sortDependenciesFirst(names, deps); err != nil {
+		return nil, fmt.Errorf("input dependencies form a cycle: %w", err)
+	}
+
+	// Each group is ordered as one unit, and so is each ungrouped input
+	unitOf := func(name string) string {
+		if group := templating.GroupOf(name); group != nil {
+			return group.Name
+		}
+		return name
+	}
+	var units []string
+	unitInputs := make(map[string][]string)
+	unitDeps := make(map[string][]string)
+	innerDeps := make(map[string][]string, len(names))
+	for _, name := range names {
+		unit := unitOf(name)
+		if _, ok := unitInputs[unit]; !ok {
+			units = append(units, unit)
+		}
+		unitInputs[unit] = append(unitInputs[unit], name)
+		for _, dep := range deps[name] {
+			if depUnit := unitOf(dep); depUnit != unit {
+				unitDeps[unit] = append(unitDeps[unit], depUnit)
+			} else {
+				innerDeps[name] = append(innerDeps[name], dep)
+			}
+		}
+	}
+	unitOrder, err := sortDependenciesFirst(units, unitDeps)
+	if err != nil {
+		return nil, fmt.Errorf("input groups depend on each other: %w", err)
+	}
+
+	order := make([]string, 0, len(names))
+	for _, unit := range unitOrder {
+		sorted, err := sortDependenciesFirst(unitInputs[unit], innerDeps)
+		if err != nil {
+			return nil, err
+		}
+		order = append(order, sorted...)
+	}
+	return order, nil
+}
+
+// sortDependenciesFirst sorts keys so that every key comes after its deps,
+// and otherwise keeps their order. A cycle is returned as an error such as
+// "a -> b -> a".
+func sortDependenciesFirst(keys []string, deps map[string][]string) ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(keys))
+	order := make([]string, 0, len(keys))
+
+	var visit func(key string, path []string) error
+	visit = func(key string, path []string) error {
+		switch state[key] {
+		case done:
+			return nil
+		case visiting:
+			cycle := append(path[indexOf(path, key):], key)
+			return errors.New(strings.Join(cycle, " -> "))
+		}
+
+		state[key] = visiting
+		for _, dep := range deps[key] {
+			if err := visit(dep, append(path, key)); err != nil {
+				return err
+			}
+		}
+		state[key] = done
+		order = append(order, key)
+		return nil
+	}
+
+	for _, key := range keys {
+		if err := visit(key, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// enabled reports whether an input's when condition holds for the given values.
+func enabled(spec *config.InputSpec, values map[string]any) (bool, error) {
+	if spec.When == "" {
+		return true, nil
+	}
+	ok, err := engine.EvalCondition(spec.When, values)
+	if err != nil {
+		return false, fmt.Errorf("evaluating when condition %q: %w", spec.When, err)
+	}
+	return ok, nil
+}
+
+// resolveDefault returns the default for an input, rendering it against the
+// values resolved so far when it is a template expression. Inputs it refers
+// to that were turned off by their when condition render empty.
+func resolveDefault(templating *config.TemplatingConfig, spec *config.InputSpec, values map[string]any) (any, error) {
+	if !isTemplate(spec.Default) {
+		return spec.DefaultValue()
+	}
+
+	rendered, err := engine.RenderString(spec.Default.(string), templating.WithZeroValues(values))
+	if err != nil {
+		return nil, err
+	}
+	return spec.Coerce(rendered)
+}
+
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+package inputs
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func TestOrder_PlacesDependenciesFirst(t *testing.T) {
+	templating := &config.TemplatingConfig{
+		Inputs: config.Inputs{
+			{Name: "a_slug", Default: "{{ .name | kebab }}"},
+			{Name: "b_module", Default: "github.com/{{ .org }}/{{ .a_slug }}"},
+			{Name: "name", Default: "My App"},
+			{Name: "org", Default: "acme"},
+			{Name: "z_comment", Default: "plain"},
+		},
+	}
+
+	order, err := Order(templating)
+	if err != nil {
+		t.Fatalf("Order failed: %v", err)
+	}
+
+	want := []string{"name", "a_slug", "org", "b_module", "z_comment"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+}
+
+func TestOrder_DetectsCycles(t *testing.T) {
+	templating := &config.TemplatingConfig{
+		Inputs: config.Inputs{
+			{Name: "a", Default: "{{ .b }}"},
+			{Name: "b", Default: "{{ .c }}"},
+			{Name: "c", Default: "{{ .a }}"},
+		},
+	}
+
+	_, err := Order(templating)
+	if err == nil {
+		t.Fatal("expected cycle error")
+	}
+	if !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Errorf("error should describe the cycle, got %v", err)
+	}
+}
+
+func TestCollect_DerivedDefaultsUseEarlierAnswers(t *testing.T) {
+	templating := &config.TemplatingConfig{
+		Inputs: config.Inputs{
+			{Name: "name", Type: config.InputTypeString, Default: "My App"},
+			{Name: "slug", Type: config.InputTypeString, Default: "{{ .name | kebab }}"},
+			{Name: "replicas", Type: config.InputTypeInt, Default: "{{ if eq .slug \"big-service\" }}5{{ else }}1{{ end }}"},
+		},
+	}
+
+	// Quiet mode derives from the defaults.
+	values, err := Collect(templating, Options{})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if values["slug"] != "my-app" || values["replicas"] != 1 {
+		t.Errorf("quiet values = %v", values)
+	}
+
+	// Interactive mode recomputes the default from the user's answer.
+	in := strings.NewReader("Big Service\n\n\n")
+	var out bytes.Buffer
+	values, err = Collect(templating, Options{Prompter: NewPrompter(in, &out)})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if values["slug"] != "big-service" || values["replicas"] != 5 {
+		t.Errorf("interactive values = %v", values)
+	}
+	if !strings.Contains(out.String(), "slug (default: big-service)") {
+		t.Errorf("expected derived default in prompt, got %q", out.String())
+	}
+}
@@ -0,0 +1,73 @@
+package inputs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+// WriteMarkdown writes a Markdown reference of the template's inputs. Inputs
+// are listed in declaration order, ungrouped inputs first and then one section
+// per group.
+func WriteMarkdown(w io.Writer, templating *config.TemplatingConfig) error {
+	if len(templating.Inputs) > 0 {
+		if err := writeSection(w, "Inputs", "", templating.Inputs); err != nil {
+			return err
+		}
+	}
+	for _, group := range templating.Groups {
+		if err := writeSection(w, group.Name, group.Help, group.Inputs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeSection(w io.Writer, title, help string, specs config.Inputs) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## %s\n\n", title)
+	if help != "" {
+		fmt.Fprintf(&b, "%s\n\n", help)
+	}
+	b.WriteString("| Name | Type | Default | Required | Description |\n")
+	b.WriteString("| ---- | ---- | ------- | -------- | ----------- |\n")
+	for _, spec := range specs {
+		typeName := string(spec.Type)
+		if spec.Type == config.InputTypeChoice {
+			typeName = fmt.Sprintf("%s (%s)", spec.Type, strings.Join(spec.Choices, ", "))
+		}
+
+		defaultVal := ""
+		if spec.Default != nil {
+			defaultVal = "`" + displayValue(spec, spec.Default) + "`"
+		}
+		if spec.Secret {
+			typeName += ", secret"
+		}
+
+		required := ""
+		if spec.Required {
+			required = "yes"
+		}
+
+		description := spec.Description
+		if spec.When != "" {
+			description = strings.TrimSpace(fmt.Sprintf("%s (only when `%s`)", description, spec.When))
+		}
+
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n",
+			spec.Name, escapeCell(typeName), escapeCell(defaultVal), required, escapeCell(description))
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeCell keeps a value from breaking a Markdown table row.
+func escapeCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", " ")
+}
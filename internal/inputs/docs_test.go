@@ -0,0 +1,61 @@
+package inputs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func TestWriteMarkdown_HonorsOrderAndGroups(t *testing.T) {
+	templating := &config.TemplatingConfig{
+		Inputs: config.Inputs{
+			{Name: "name", Type: config.InputTypeString, Default: "My App", Required: true, Description: "Project name"},
+			{Name: "slug", Type: config.InputTypeString, Default: "{{ .name | kebab }}"},
+		},
+		Groups: []config.InputGroup{
+			{
+				Name: "CI",
+				Help: "Continuous integration settings",
+				Inputs: config.Inputs{
+					{Name: "ci_provider", Type: config.InputTypeChoice, Choices: []string{"github", "gitlab"}, Default: "github"},
+				},
+			},
+			{
+				Name: "Deployment",
+				Inputs: config.Inputs{
+					{Name: "registry", Type: config.InputTypeString, When: ".use_docker", Description: "Image registry"},
+				},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := WriteMarkdown(&out, templating); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	doc := out.String()
+
+	ordered := []string{
+		"## Inputs",
+		"| `name` | string | `My App` | yes | Project name |",
+		"| `slug` | string | `{{ .name \\| kebab }}` |",
+		"## CI",
+		"Continuous integration settings",
+		"| `ci_provider` | choice (github, gitlab) | `github` |",
+		"## Deployment",
+		"Image registry (only when `.use_docker`)",
+	}
+	last := -1
+	for _, want := range ordered {
+		idx := strings.Index(doc, want)
+		if idx < 0 {
+			t.Fatalf("expected %q in:\n%s", want, doc)
+		}
+		if idx < last {
+			t.Errorf("%q is out of order in:\n%s", want, doc)
+		}
+		last = idx
+	}
+}
@@ -0,0 +1,239 @@
+package inputs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/faradayfan/sygkro/internal/config"
+	"golang.org/x/term"
+)
+
+// Prompter asks the user for input values on a reader/writer pair, typically
+// stdin and stdout.
+type Prompter struct {
+	reader *bufio.Reader
+	out    io.Writer
+
+	// readSecret reads a line without echo. It is only set when the input is
+	// a terminal; otherwise secrets are read like any other line.
+	readSecret func() (string, error)
+}
+
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	p := &Prompter{
+		reader: bufio.NewReader(in),
+		out:    out,
+	}
+
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		p.readSecret = func() (string, error) {
+			b, err := term.ReadPassword(int(f.Fd()))
+			fmt.Fprintln(p.out)
+			return string(b) + "\n", err
+		}
+	}
+
+	return p
+}
+
+// readLine reads one answer, without echo for secret inputs when possible.
+func (p *Prompter) readLine(secret bool) (string, error) {
+	if secret && p.readSecret != nil {
+		return p.readSecret()
+	}
+	return p.reader.ReadString('\n')
+}
+
+// Section prints the header and help text of an input group.
+func (p *Prompter) Section(group *config.InputGroup) {
+	fmt.Fprintf(p.out, "\n== %s ==\n", group.Name)
+	if group.Help != "" {
+		fmt.Fprintf(p.out, "%s\n", group.Help)
+	}
+}
+
+// Ask prompts for a single input until the answer parses as the input's type
+// and passes validate. An empty answer selects defaultVal. When validate is
+// nil the input's own constraints are checked.
+func (p *Prompter) Ask(spec *config.InputSpec, defaultVal any, validate func(value any) []string) (any, error) {
+	name := spec.Name
+	if validate == nil {
+		validate = spec.Validate
+	}
+
+	for {
+		if spec.Description != "" {
+			fmt.Fprintf(p.out, "# %s\n", spec.Description)
+		}
+		fmt.Fprintf(p.out, "%s%s (default: %s): ", spec.Label(), hint(spec), displayValue(spec, defaultVal))
+
+		line, err := p.readLine(spec.Secret)
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, fmt.Errorf("error reading input for %s: %w", name, err)
+		}
+
+		value := defaultVal
+		if answer := strings.TrimSpace(line); answer != "" {
+			value, err = spec.Parse(answer)
+			if err != nil {
+				fmt.Fprintf(p.out, "  %s: %v\n", name, err)
+				continue
+			}
+		}
+
+		if problems := validate(value); len(problems) > 0 {
+			for _, problem := range problems {
+				fmt.Fprintf(p.out, "  %s: %s\n", name, problem)
+			}
+			continue
+		}
+		return value, nil
+	}
+}
+
+// displayValue formats a value for display, masking secrets.
+func displayValue(spec *config.InputSpec, value any) string {
+	if spec.Secret && !config.IsEmpty(value) {
+		return "********"
+	}
+	return config.FormatValue(value)
+}
+
+// hint returns a short description of the accepted values for a prompt.
+func hint(spec *config.InputSpec) string {
+	switch spec.Type {
+	case config.InputTypeBool:
+		return " [y/n]"
+	case config.InputTypeInt:
+		return " [int]"
+	case config.InputTypeChoice:
+		return " [" + strings.Join(spec.Choices, "/") + "]"
+	case config.InputTypeList:
+		return " [comma separated]"
+	case config.InputTypeMap:
+		return " [key=value,...]"
+	default:
+		return ""
+	}
+}
+
+// Options controls where Collect takes input values from.
+type Options struct {
+	// Prompter asks for every input that was not supplied. When nil, Collect
+	// does not prompt.
+	Prompter *Prompter
+	// Supplied holds values provided without prompting (see Supplied). They
+	// take precedence over prompting and defaults.
+	Supplied map[string]any
+	// RequireSupplied makes an input that was neither supplied nor prompted
+	// for a violation, instead of falling back to its default.
+	RequireSupplied bool
+	// Defaults replace the template's defaults for the inputs they name, e.g.
+	// the default answers of the user config. Names the template does not
+	// declare are ignored.
+	Defaults map[string]any
+}
+
+// Collect resolves a typed value for every input of the template, in
+// declaration order except where derived defaults and when conditions refer to
+// later inputs, which are then resolved first. Each group's header is printed
+// before its first input. Inputs whose when condition is false are skipped.
+//
+// Supplied values are used as given. Other inputs are prompted for, and
+// re-asked until valid, when a prompter is set; otherwise they take their
+// defaults, or are reported as missing when RequireSupplied is set. In every
+// mode the final set of values is validated and a *config.ValidationError
+// lists every violation.
+func Collect(templating *config.TemplatingConfig, opts Options) (map[string]any, error) {
+	names, err := Order(templating)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []config.Violation
+	values := make(map[string]any, len(names))
+	var currentGroup *config.InputGroup
+	for _, name := range names {
+		spec := templating.Input(name)
+
+		// Inputs whose condition is false are skipped and left out of the values.
+		ok, err := enabled(spec, values)
+		if err != nil {
+			return nil, fmt.Errorf("input %s: %w", name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		// Blank values, e.g. from SYGKRO_INPUT_USE_DOCKER=, count as not supplied
+		if raw, ok := opts.Supplied[name]; ok && !spec.Unset(raw) {
+			value, err := spec.Coerce(raw)
+			if err != nil {
+				violations = append(violations, config.Violation{Input: name, Message: err.Error()})
+				continue
+			}
+			values[name] = value
+			continue
+		}
+
+		// Derived defaults are rendered against the answers given so far.
+		var defaultVal any
+		if userDefault, ok := opts.Defaults[name]; ok && !spec.Unset(userDefault) {
+			defaultVal, err = spec.Coerce(userDefault)
+		} else {
+			defaultVal, err = resolveDefault(templating, spec, values)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid default for input %s: %w", name, err)
+		}
+
+		if opts.Prompter == nil {
+			if opts.RequireSupplied {
+				violations = append(violations, config.Violation{Input: name, Message: "was not supplied"})
+				continue
+			}
+			values[name] = defaultVal
+			continue
+		}
+
+		if group := templating.GroupOf(name); group != nil && group != currentGroup {
+			opts.Prompter.Section(group)
+			currentGroup = group
+		}
+
+		value, err := opts.Prompter.Ask(spec, defaultVal, func(value any) []string {
+			return validateInput(templating, name, value, values)
+		})
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+
+	if err := Validate(templating, values); err != nil {
+		var validationErr *config.ValidationError
+		if !errors.As(err, &validationErr) {
+			return nil, err
+		}
+		// Inputs that already failed above would only be reported again as missing.
+		reported := make(map[string]bool, len(violations))
+		for _, v := range violations {
+			reported[v.Input] = true
+		}
+		for _, v := range validationErr.Violations {
+			if v.Input == "" || !reported[v.Input] {
+				violations = append(violations, v)
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return nil, &config.ValidationError{Violations: violations}
+	}
+
+	return values, nil
+}
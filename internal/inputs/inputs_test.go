@@ -0,0 +1,214 @@
+package inputs
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func TestCollect_QuietUsesTypedDefaults(t *testing.T) {
+	specs := config.Inputs{
+		{Name: "name", Type: config.InputTypeString, Default: "my-app"},
+		{Name: "use_docker", Type: config.InputTypeBool, Default: "true"},
+		{Name: "services", Type: config.InputTypeList, Default: []any{"api"}},
+	}
+
+	values, err := Collect(&config.TemplatingConfig{Inputs: specs}, Options{})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	want := map[string]any{
+		"name":       "my-app",
+		"use_docker": true,
+		"services":   []any{"api"},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %#v, want %#v", values, want)
+	}
+}
+
+func TestCollect_QuietFailsOnRequiredWithoutDefault(t *testing.T) {
+	specs := config.Inputs{
+		{Name: "owner", Type: config.InputTypeString, Required: true},
+	}
+
+	if _, err := Collect(&config.TemplatingConfig{Inputs: specs}, Options{}); err == nil {
+		t.Error("expected error for required input without default")
+	}
+}
+
+func TestCollect_DefaultsReplaceTemplateDefaults(t *testing.T) {
+	specs := config.Inputs{
+		{Name: "name", Type: config.InputTypeString, Default: "my-app"},
+		{Name: "slug", Type: config.InputTypeString, Default: "{{ .name }}"},
+		{Name: "author", Type: config.InputTypeString, Default: "Your Name"},
+		{Name: "replicas", Type: config.InputTypeInt, Default: 1},
+	}
+	defaults := map[string]any{"author": "Jane Doe", "replicas": "3", "org": "acme"}
+
+	values, err := Collect(&config.TemplatingConfig{Inputs: specs}, Options{Defaults: defaults})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	want := map[string]any{"name": "my-app", "slug": "my-app", "author": "Jane Doe", "replicas": 3}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %#v, want %#v", values, want)
+	}
+}
+
+func TestCollect_PromptsAndParses(t *testing.T) {
+	specs := config.Inputs{
+		{Name: "port", Type: config.InputTypeInt, Default: 8080},
+		{Name: "services", Type: config.InputTypeList},
+		{Name: "verbose", Type: config.InputTypeBool, Default: false},
+	}
+
+	// Prompts are asked in declaration order: port, services, verbose.
+	in := strings.NewReader("\napi,worker\ny\n")
+	var out bytes.Buffer
+
+	values, err := Collect(&config.TemplatingConfig{Inputs: specs}, Options{Prompter: NewPrompter(in, &out)})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	want := map[string]any{
+		"port":     8080,
+		"services": []any{"api", "worker"},
+		"verbose":  true,
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %#v, want %#v", values, want)
+	}
+}
+
+func TestPrompter_AskReasksOnInvalidValue(t *testing.T) {
+	spec := &config.InputSpec{Name: "env", Type: config.InputTypeChoice, Choices: []string{"dev", "prod"}, Required: true}
+	in := strings.NewReader("\nstaging\nprod\n")
+	var out bytes.Buffer
+
+	value, err := NewPrompter(in, &out).Ask(spec, "", nil)
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	if value != "prod" {
+		t.Errorf("value = %v, want prod", value)
+	}
+	if !strings.Contains(out.String(), "env: is required") {
+		t.Errorf("expected required message, got %q", out.String())
+	}
+	if !strings.Contains(out.String(), "env: \"staging\" is not one of") {
+		t.Errorf("expected parse error message, got %q", out.String())
+	}
+	if !strings.Contains(out.String(), "env [dev/prod]") {
+		t.Errorf("expected choices hint, got %q", out.String())
+	}
+}
+
+func TestPrompter_AskFailsAtEOF(t *testing.T) {
+	spec := &config.InputSpec{Name: "port", Type: config.InputTypeInt}
+	var out bytes.Buffer
+
+	if _, err := NewPrompter(strings.NewReader("abc"), &out).Ask(spec, 1, nil); err == nil {
+		t.Error("expected error when input runs out")
+	}
+}
+
+func TestCollect_PrintsGroupHeaders(t *testing.T) {
+	templating := &config.TemplatingConfig{
+		Inputs: config.Inputs{
+			{Name: "name", Type: config.InputTypeString, Default: "app"},
+		},
+		Groups: []config.InputGroup{
+			{Name: "CI", Help: "Continuous integration", Inputs: config.Inputs{
+				{Name: "ci", Type: config.InputTypeBool, Default: true},
+			}},
+			{Name: "Deployment", Inputs: config.Inputs{
+				{Name: "replicas", Type: config.InputTypeInt, Default: 1},
+			}},
+		},
+	}
+	in := strings.NewReader("\n\n\n")
+	var out bytes.Buffer
+
+	if _, err := Collect(templating, Options{Prompter: NewPrompter(in, &out)}); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	got := out.String()
+	nameIdx := strings.Index(got, "name (default")
+	ciIdx := strings.Index(got, "== CI ==\nContinuous integration\n")
+	deployIdx := strings.Index(got, "== Deployment ==")
+	replicasIdx := strings.Index(got, "replicas [int]")
+	if nameIdx < 0 || ciIdx < nameIdx || deployIdx < ciIdx || replicasIdx < deployIdx {
+		t.Errorf("prompts out of order:\n%s", got)
+	}
+}
+
+func TestCollect_KeepsGroupsTogether(t *testing.T) {
+	templating := &config.TemplatingConfig{
+		Groups: []config.InputGroup{
+			{Name: "A", Inputs: config.Inputs{
+				{Name: "a1", Type: config.InputTypeString, Default: "{{ .b1 }}"},
+				{Name: "a2", Type: config.InputTypeString, Default: "a2"},
+			}},
+			{Name: "B", Inputs: config.Inputs{
+				{Name: "b2", Type: config.InputTypeString, Default: "b2"},
+				{Name: "b1", Type: config.InputTypeString, Default: "b1"},
+			}},
+		},
+	}
+	in := strings.NewReader("\n\n\n\n")
+	var out bytes.Buffer
+
+	values, err := Collect(templating, Options{Prompter: NewPrompter(in, &out)})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if values["a1"] != "b1" {
+		t.Errorf("a1 = %v, want the answer to b1", values["a1"])
+	}
+
+	got := out.String()
+	if strings.Count(got, "== A ==") != 1 || strings.Count(got, "== B ==") != 1 {
+		t.Fatalf("expected every group header once:\n%s", got)
+	}
+	indexes := []int{
+		strings.Index(got, "== B =="),
+		strings.Index(got, "b2 (default"),
+		strings.Index(got, "b1 (default"),
+		strings.Index(got, "== A =="),
+		strings.Index(got, "a1 (default"),
+		strings.Index(got, "a2 (default"),
+	}
+	for i := 1; i < len(indexes); i++ {
+		if indexes[i-1] < 0 || indexes[i] < indexes[i-1] {
+			t.Fatalf("prompts out of order:\n%s", got)
+		}
+	}
+}
+
+func TestOrder_DetectsCyclesBetweenGroups(t *testing.T) {
+	templating := &config.TemplatingConfig{
+		Groups: []config.InputGroup{
+			{Name: "A", Inputs: config.Inputs{
+				{Name: "a1", Default: "{{ .b1 }}"},
+				{Name: "a2", Default: "a2"},
+			}},
+			{Name: "B", Inputs: config.Inputs{
+				{Name: "b1", Default: "b1"},
+				{Name: "b2", Default: "{{ .a2 }}"},
+			}},
+		},
+	}
+
+	_, err := Order(templating)
+	if err == nil || !strings.Contains(err.Error(), "A -> B -> A") {
+		t.Errorf("expected a cycle between the groups, got %v", err)
+	}
+}
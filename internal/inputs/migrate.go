@@ -0,0 +1,84 @@
+package inputs
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/faradayfan/sygkro/internal/config"
+	"github.com/faradayfan/sygkro/internal/engine"
+)
+
+// PendingMigrations returns the migrations of the current template version
+// that the previous version did not declare, in declaration order. These are
+// the migrations a project synced at the previous version has not seen yet.
+func PendingMigrations(current, previous []config.Migration) []config.Migration {
+	seen := make(map[string]bool, len(previous))
+	for _, m := range previous {
+		seen[m.ID] = true
+	}
+
+	var pending []config.Migration
+	for _, m := range current {
+		if !seen[m.ID] {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// Migrate returns a copy of stored with the migrations applied in order,
+// for the template whose inputs are templating. A rename whose source is
+// missing, or whose target already has a value, only drops the source;
+// set_default leaves existing values alone; compute always overwrites, so it
+// can also rewrite a value into a new form, and its result is coerced to the
+// type of the input it sets. Stored inputs the template no longer declares
+// are left out of the copy and returned, in sorted order, so that they can be
+// reported.
+func Migrate(templating *config.TemplatingConfig, migrations []config.Migration, stored map[string]any) (map[string]any, []string, error) {
+	values := make(map[string]any, len(stored))
+	for name, value := range stored {
+		values[name] = value
+	}
+
+	for _, m := range migrations {
+		switch {
+		case m.Rename != nil:
+			if value, ok := values[m.Rename.From]; ok {
+				if _, exists := values[m.Rename.To]; !exists {
+					values[m.Rename.To] = value
+				}
+				delete(values, m.Rename.From)
+			}
+		case m.Drop != "":
+			delete(values, m.Drop)
+		case m.SetDefault != nil:
+			if _, ok := values[m.SetDefault.Input]; !ok {
+				values[m.SetDefault.Input] = m.SetDefault.Value
+			}
+		case m.Compute != nil:
+			tmplStr, _ := m.Compute.Value.(string)
+			rendered, err := engine.RenderString(tmplStr, values)
+			if err != nil {
+				return nil, nil, fmt.Errorf("migration %s: %w", m.ID, err)
+			}
+			var value any = rendered
+			if spec := templating.Input(m.Compute.Input); spec != nil {
+				if value, err = spec.Coerce(rendered); err != nil {
+					return nil, nil, fmt.Errorf("migration %s: input %s: %w", m.ID, m.Compute.Input, err)
+				}
+			}
+			values[m.Compute.Input] = value
+		}
+	}
+
+	var dropped []string
+	for name := range values {
+		if templating.Input(name) == nil {
+			dropped = append(dropped, name)
+			delete(values, name)
+		}
+	}
+	sort.Strings(dropped)
+
+	return values, dropped, nil
+}
@@ -0,0 +1,125 @@
+package inputs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func TestPendingMigrations(t *testing.T) {
+	previous := []config.Migration{{ID: "a"}, {ID: "b"}}
+	current := []config.Migration{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+
+	var ids []string
+	for _, m := range PendingMigrations(current, previous) {
+		ids = append(ids, m.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"c", "d"}) {
+		t.Errorf("pending = %v, want [c d]", ids)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	stored := map[string]any{
+		"project_name": "Billing API",
+		"use_travis":   true,
+		"license":      "Apache-2.0",
+	}
+	migrations := []config.Migration{
+		{ID: "rename", Rename: &config.RenameStep{From: "project_name", To: "name"}},
+		{ID: "drop", Drop: "use_travis"},
+		{ID: "license", SetDefault: &config.ValueStep{Input: "license", Value: "MIT"}},
+		{ID: "ci", SetDefault: &config.ValueStep{Input: "ci", Value: "github"}},
+		{ID: "slug", Compute: &config.ValueStep{Input: "slug", Value: "{{ .name | kebab }}"}},
+	}
+
+	templating := &config.TemplatingConfig{Inputs: config.Inputs{
+		{Name: "name"}, {Name: "license"}, {Name: "ci"}, {Name: "slug"},
+	}}
+
+	got, dropped, err := Migrate(templating, migrations, stored)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(dropped) != 0 {
+		t.Errorf("dropped = %v, want none", dropped)
+	}
+
+	want := map[string]any{
+		"name":    "Billing API",
+		"license": "Apache-2.0",
+		"ci":      "github",
+		"slug":    "billing-api",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Migrate = %#v, want %#v", got, want)
+	}
+	if _, ok := stored["project_name"]; !ok {
+		t.Error("Migrate modified the stored inputs")
+	}
+}
+
+func TestMigrate_RenameKeepsExistingTarget(t *testing.T) {
+	stored := map[string]any{"old": "a", "new": "b"}
+	migrations := []config.Migration{{ID: "r", Rename: &config.RenameStep{From: "old", To: "new"}}}
+
+	templating := &config.TemplatingConfig{Inputs: config.Inputs{{Name: "new"}}}
+
+	got, _, err := Migrate(templating, migrations, stored)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, map[string]any{"new": "b"}) {
+		t.Errorf("Migrate = %#v", got)
+	}
+}
+
+func TestMigrate_ComputeError(t *testing.T) {
+	migrations := []config.Migration{{ID: "bad", Compute: &config.ValueStep{Input: "x", Value: "{{ .a | nope }}"}}}
+	if _, _, err := Migrate(&config.TemplatingConfig{}, migrations, nil); err == nil {
+		t.Error("expected error for an invalid compute template")
+	}
+}
+
+func TestMigrate_ComputeCoercesToTheInputType(t *testing.T) {
+	templating := &config.TemplatingConfig{Inputs: config.Inputs{
+		{Name: "ci", Type: config.InputTypeChoice, Choices: []string{"none", "github"}},
+		{Name: "use_ci", Type: config.InputTypeBool},
+		{Name: "replicas", Type: config.InputTypeInt},
+	}}
+	migrations := []config.Migration{
+		{ID: "use_ci", Compute: &config.ValueStep{Input: "use_ci", Value: `{{ ne .ci "none" }}`}},
+		{ID: "replicas", Compute: &config.ValueStep{Input: "replicas", Value: "{{ if .use_ci }}3{{ else }}1{{ end }}"}},
+	}
+
+	got, _, err := Migrate(templating, migrations, map[string]any{"ci": "github"})
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if want := map[string]any{"ci": "github", "use_ci": true, "replicas": 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Migrate = %#v, want %#v", got, want)
+	}
+
+	bad := []config.Migration{{ID: "bad", Compute: &config.ValueStep{Input: "replicas", Value: "many"}}}
+	if _, _, err := Migrate(templating, bad, nil); err == nil {
+		t.Error("expected an error for a computed value that is not an integer")
+	}
+}
+
+func TestMigrate_ReportsUndeclaredInputs(t *testing.T) {
+	templating := &config.TemplatingConfig{Inputs: config.Inputs{{Name: "name"}}}
+	stored := map[string]any{"name": "shop", "use_travis": true, "legacy": "x", "old": "y"}
+	migrations := []config.Migration{{ID: "drop", Drop: "old"}}
+
+	got, dropped, err := Migrate(templating, migrations, stored)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, map[string]any{"name": "shop"}) {
+		t.Errorf("Migrate = %#v", got)
+	}
+	if want := []string{"legacy", "use_travis"}; !reflect.DeepEqual(dropped, want) {
+		t.Errorf("dropped = %v, want %v", dropped, want)
+	}
+}
@@ -0,0 +1,82 @@
+package inputs
+
+import (
+	"fmt"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+// WithoutSecrets returns a copy of values without the template's secret
+// inputs, suitable for writing to the sync config.
+func WithoutSecrets(templating *config.TemplatingConfig, values map[string]any) map[string]any {
+	result := make(map[string]any, len(values))
+	for name, value := range values {
+		if spec := templating.Input(name); spec != nil && spec.Secret {
+			continue
+		}
+		result[name] = value
+	}
+	return result
+}
+
+// ResolveSecrets returns a copy of stored with a value for every enabled
+// secret input, which the sync config never contains. Each secret is taken
+// from its SYGKRO_INPUT_<NAME> environment variable (looked up with
+// lookupEnv) or, failing that, asked for with prompter. Without a prompter a
+// missing secret is an error.
+func ResolveSecrets(templating *config.TemplatingConfig, stored map[string]any, lookupEnv func(string) (string, bool), prompter *Prompter) (map[string]any, error) {
+	values := make(map[string]any, len(stored))
+	for name, value := range stored {
+		values[name] = value
+	}
+
+	names, err := Order(templating)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		spec := templating.Input(name)
+		if !spec.Secret {
+			continue
+		}
+		if _, ok := values[name]; ok {
+			continue
+		}
+
+		ok, err := enabled(spec, values)
+		if err != nil {
+			return nil, fmt.Errorf("input %s: %w", name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		if lookupEnv != nil {
+			if raw, ok := lookupEnv(EnvName(name)); ok && !spec.Unset(raw) {
+				value, err := spec.Coerce(raw)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value for secret input %s in %s: %w", name, EnvName(name), err)
+				}
+				values[name] = value
+				continue
+			}
+		}
+
+		if prompter == nil {
+			return nil, fmt.Errorf("secret input %s is not stored in the sync config; set %s to supply it", name, EnvName(name))
+		}
+
+		defaultVal, err := resolveDefault(templating, spec, values)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default for input %s: %w", name, err)
+		}
+		value, err := prompter.Ask(spec, defaultVal, nil)
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
@@ -0,0 +1,106 @@
+package inputs
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func secretTemplating() *config.TemplatingConfig {
+	return &config.TemplatingConfig{
+		Inputs: config.Inputs{
+			{Name: "name", Type: config.InputTypeString, Default: "app"},
+			{Name: "use_registry", Type: config.InputTypeBool, Default: true},
+			{Name: "token", Type: config.InputTypeString, Secret: true, When: ".use_registry"},
+		},
+	}
+}
+
+func TestWithoutSecrets(t *testing.T) {
+	values := map[string]any{"name": "app", "use_registry": true, "token": "s3cret"}
+
+	got := WithoutSecrets(secretTemplating(), values)
+
+	want := map[string]any{"name": "app", "use_registry": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WithoutSecrets = %#v, want %#v", got, want)
+	}
+	if _, ok := values["token"]; !ok {
+		t.Error("WithoutSecrets modified its argument")
+	}
+}
+
+func TestResolveSecrets_FromEnv(t *testing.T) {
+	stored := map[string]any{"name": "app", "use_registry": true}
+	lookupEnv := func(key string) (string, bool) {
+		if key == "SYGKRO_INPUT_TOKEN" {
+			return "from-env", true
+		}
+		return "", false
+	}
+
+	got, err := ResolveSecrets(secretTemplating(), stored, lookupEnv, nil)
+	if err != nil {
+		t.Fatalf("ResolveSecrets failed: %v", err)
+	}
+	if got["token"] != "from-env" {
+		t.Errorf("token = %#v, want from-env", got["token"])
+	}
+	if _, ok := stored["token"]; ok {
+		t.Error("ResolveSecrets modified the stored inputs")
+	}
+}
+
+func TestResolveSecrets_Prompts(t *testing.T) {
+	stored := map[string]any{"name": "app", "use_registry": true}
+	var out bytes.Buffer
+
+	got, err := ResolveSecrets(secretTemplating(), stored, nil, NewPrompter(strings.NewReader("typed\n"), &out))
+	if err != nil {
+		t.Fatalf("ResolveSecrets failed: %v", err)
+	}
+	if got["token"] != "typed" {
+		t.Errorf("token = %#v, want typed", got["token"])
+	}
+}
+
+func TestResolveSecrets_MissingWithoutPrompter(t *testing.T) {
+	stored := map[string]any{"name": "app", "use_registry": true}
+
+	_, err := ResolveSecrets(secretTemplating(), stored, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "SYGKRO_INPUT_TOKEN") {
+		t.Errorf("expected error naming SYGKRO_INPUT_TOKEN, got %v", err)
+	}
+}
+
+func TestResolveSecrets_SkipsDisabledSecrets(t *testing.T) {
+	stored := map[string]any{"name": "app", "use_registry": false}
+
+	got, err := ResolveSecrets(secretTemplating(), stored, nil, nil)
+	if err != nil {
+		t.Fatalf("ResolveSecrets failed: %v", err)
+	}
+	if _, ok := got["token"]; ok {
+		t.Error("disabled secret should not be resolved")
+	}
+}
+
+func TestAsk_MasksSecretDefault(t *testing.T) {
+	var out bytes.Buffer
+	p := NewPrompter(strings.NewReader("\n"), &out)
+	spec := &config.InputSpec{Name: "token", Type: config.InputTypeString, Secret: true}
+
+	got, err := p.Ask(spec, "s3cret", nil)
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	if got != "s3cret" {
+		t.Errorf("Ask = %#v, want default", got)
+	}
+	if strings.Contains(out.String(), "s3cret") || !strings.Contains(out.String(), "********") {
+		t.Errorf("prompt should mask the default, got %q", out.String())
+	}
+}
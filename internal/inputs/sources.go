@@ -0,0 +1,111 @@
+package inputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/faradayfan/sygkro/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of environment variables that supply input values,
+// e.g. SYGKRO_INPUT_USE_DOCKER for the use_docker input.
+const EnvPrefix = "SYGKRO_INPUT_"
+
+// EnvName returns the environment variable that supplies the named input.
+func EnvName(name string) string {
+	return EnvPrefix + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// Supplied gathers the input values provided without prompting. Sources are
+// applied in increasing order of precedence:
+//
+//  1. SYGKRO_INPUT_<NAME> environment variables (looked up with lookupEnv)
+//  2. the answers file at answersFile (YAML, or JSON for a .json file)
+//  3. key=value pairs from --input flags
+//
+// Values naming an input the template does not declare are an error.
+func Supplied(templating *config.TemplatingConfig, pairs []string, answersFile string, lookupEnv func(string) (string, bool)) (map[string]any, error) {
+	supplied := make(map[string]any)
+
+	if lookupEnv != nil {
+		for _, spec := range templating.AllInputs() {
+			if value, ok := lookupEnv(EnvName(spec.Name)); ok {
+				supplied[spec.Name] = value
+			}
+		}
+	}
+
+	if answersFile != "" {
+		answers, err := readAnswersFile(answersFile)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range answers {
+			if templating.Input(name) == nil {
+				return nil, fmt.Errorf("answers file %s: unknown input %s", answersFile, name)
+			}
+			supplied[name] = value
+		}
+	}
+
+	flagged, err := ParsePairs(templating, pairs)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range flagged {
+		supplied[name] = value
+	}
+
+	return supplied, nil
+}
+
+// ParsePairs parses key=value pairs given on the command line into raw input
+// values. Keys naming an input the template does not declare are an error.
+func ParsePairs(templating *config.TemplatingConfig, pairs []string) (map[string]any, error) {
+	values := make(map[string]any, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid input %q: expected key=value", pair)
+		}
+		name = strings.TrimSpace(name)
+		if templating.Input(name) == nil {
+			return nil, fmt.Errorf("invalid input %q: unknown input %s", pair, name)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// readAnswersFile decodes a YAML or JSON mapping of input names to values.
+func readAnswersFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers file: %w", err)
+	}
+
+	answers := make(map[string]any)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &answers)
+	} else {
+		err = yaml.Unmarshal(data, &answers)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode answers file %s: %w", path, err)
+	}
+
+	return answers, nil
+}
@@ -0,0 +1,155 @@
+package inputs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func sourcesTemplating() *config.TemplatingConfig {
+	return &config.TemplatingConfig{
+		Inputs: config.Inputs{
+			{Name: "name", Type: config.InputTypeString, Default: "app"},
+			{Name: "use-docker", Type: config.InputTypeBool, Default: false},
+			{Name: "replicas", Type: config.InputTypeInt, Default: 1},
+			{Name: "services", Type: config.InputTypeList},
+		},
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("use-docker"); got != "SYGKRO_INPUT_USE_DOCKER" {
+		t.Errorf("EnvName = %q", got)
+	}
+}
+
+func TestSupplied_Precedence(t *testing.T) {
+	answers := filepath.Join(t.TempDir(), "answers.yaml")
+	if err := os.WriteFile(answers, []byte("name: from-file\nreplicas: 3\nservices: [api, worker]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{
+		"SYGKRO_INPUT_NAME":       "from-env",
+		"SYGKRO_INPUT_USE_DOCKER": "yes",
+		"SYGKRO_INPUT_REPLICAS":   "2",
+	}
+	lookupEnv := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	supplied, err := Supplied(sourcesTemplating(), []string{"name=from-flag"}, answers, lookupEnv)
+	if err != nil {
+		t.Fatalf("Supplied failed: %v", err)
+	}
+
+	want := map[string]any{
+		"name":       "from-flag",
+		"use-docker": "yes",
+		"replicas":   3,
+		"services":   []any{"api", "worker"},
+	}
+	if !reflect.DeepEqual(supplied, want) {
+		t.Errorf("supplied = %#v, want %#v", supplied, want)
+	}
+}
+
+func TestSupplied_JSONAnswersFile(t *testing.T) {
+	answers := filepath.Join(t.TempDir(), "answers.json")
+	if err := os.WriteFile(answers, []byte(`{"replicas": 4, "use-docker": true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	supplied, err := Supplied(sourcesTemplating(), nil, answers, nil)
+	if err != nil {
+		t.Fatalf("Supplied failed: %v", err)
+	}
+
+	values, err := Collect(sourcesTemplating(), Options{Supplied: supplied})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if values["replicas"] != 4 || values["use-docker"] != true {
+		t.Errorf("values = %#v", values)
+	}
+}
+
+func TestSupplied_RejectsUnknownAndMalformed(t *testing.T) {
+	if _, err := Supplied(sourcesTemplating(), []string{"nmae=typo"}, "", nil); err == nil {
+		t.Error("expected error for unknown input")
+	}
+	if _, err := Supplied(sourcesTemplating(), []string{"name"}, "", nil); err == nil {
+		t.Error("expected error for missing '='")
+	}
+}
+
+func TestCollect_RequireSuppliedListsMissingInputs(t *testing.T) {
+	supplied := map[string]any{"name": "svc", "replicas": "lots"}
+
+	_, err := Collect(sourcesTemplating(), Options{Supplied: supplied, RequireSupplied: true})
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected *config.ValidationError, got %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, v := range validationErr.Violations {
+		got[v.Input] = v.Message
+	}
+	if len(got) != 3 || got["use-docker"] != "was not supplied" || got["services"] != "was not supplied" || got["replicas"] == "" {
+		t.Errorf("violations = %v", validationErr.Violations)
+	}
+}
+
+func TestParsePairs(t *testing.T) {
+	values, err := ParsePairs(sourcesTemplating(), []string{"name=a=b", " replicas =3"})
+	if err != nil {
+		t.Fatalf("ParsePairs failed: %v", err)
+	}
+	want := map[string]any{"name": "a=b", "replicas": "3"}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %#v, want %#v", values, want)
+	}
+
+	for _, bad := range []string{"name", "unknown=1"} {
+		if _, err := ParsePairs(sourcesTemplating(), []string{bad}); err == nil {
+			t.Errorf("ParsePairs(%q): expected error", bad)
+		}
+	}
+}
+
+func TestCollect_BlankSuppliedValuesAreUnset(t *testing.T) {
+	templating := sourcesTemplating()
+	templating.Inputs = append(templating.Inputs,
+		&config.InputSpec{Name: "ci", Type: config.InputTypeChoice, Choices: []string{"none", "github"}, Default: "github"})
+	answers := filepath.Join(t.TempDir(), "answers.yaml")
+	if err := os.WriteFile(answers, []byte("ci: \"\"\nreplicas:\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lookupEnv := func(key string) (string, bool) {
+		return "", key == "SYGKRO_INPUT_USE_DOCKER"
+	}
+
+	supplied, err := Supplied(templating, nil, answers, lookupEnv)
+	if err != nil {
+		t.Fatalf("Supplied failed: %v", err)
+	}
+	got, err := Collect(templating, Options{Supplied: supplied})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	want := map[string]any{"name": "app", "use-docker": false, "replicas": 1, "services": []any{}, "ci": "github"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Collect = %#v, want %#v", got, want)
+	}
+
+	_, err = Collect(templating, Options{Supplied: supplied, RequireSupplied: true})
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected blank values to count as not supplied, got %v", err)
+	}
+}
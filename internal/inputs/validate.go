@@ -0,0 +1,102 @@
+package inputs
+
+import (
+	"fmt"
+
+	"github.com/faradayfan/sygkro/internal/config"
+	"github.com/faradayfan/sygkro/internal/engine"
+)
+
+// Validate coerces every stored value to its declared type and checks it
+// against the input constraints and the template-level rules. Inputs whose
+// when condition is false are skipped. values is updated in place with the
+// coerced values, and disabled inputs are removed from it. It returns a
+// *config.ValidationError listing every violation, or nil.
+func Validate(templating *config.TemplatingConfig, values map[string]any) error {
+	var violations []config.Violation
+
+	names, err := Order(templating)
+	if err != nil {
+		return err
+	}
+
+	skipped := make(map[string]bool)
+	for _, name := range names {
+		spec := templating.Input(name)
+
+		ok, err := enabled(spec, values)
+		if err != nil {
+			violations = append(violations, config.Violation{Input: name, Message: err.Error()})
+			continue
+		}
+		if !ok {
+			// A disabled input is not part of the rendering data.
+			skipped[name] = true
+			delete(values, name)
+			continue
+		}
+
+		value, err := spec.Coerce(values[name])
+		if err != nil {
+			violations = append(violations, config.Violation{Input: name, Message: err.Error()})
+			continue
+		}
+		values[name] = value
+		for _, problem := range spec.Validate(value) {
+			violations = append(violations, config.Violation{Input: name, Message: problem})
+		}
+	}
+
+	for _, rule := range templating.Rules {
+		if skipped[rule.Input] {
+			continue
+		}
+		if violation, ok := checkRule(rule, values); !ok {
+			violations = append(violations, violation)
+		}
+	}
+
+	if len(violations) > 0 {
+		return &config.ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// validateInput checks a single answer against its constraints and against the
+// rules attached to that input, using the answers collected so far.
+func validateInput(templating *config.TemplatingConfig, name string, value any, values map[string]any) []string {
+	problems := templating.Input(name).Validate(value)
+
+	for _, rule := range templating.Rules {
+		if rule.Input != name {
+			continue
+		}
+		candidate := make(map[string]any, len(values)+1)
+		for k, v := range values {
+			candidate[k] = v
+		}
+		candidate[name] = value
+		if violation, ok := checkRule(rule, candidate); !ok {
+			problems = append(problems, violation.Message)
+		}
+	}
+
+	return problems
+}
+
+// checkRule evaluates a template-level rule and reports whether it passed.
+func checkRule(rule config.ValidationRule, values map[string]any) (config.Violation, bool) {
+	ok, err := engine.EvalCondition(rule.Rule, values)
+	if err != nil {
+		return config.Violation{Input: rule.Input, Message: fmt.Sprintf("invalid rule %q: %v", rule.Rule, err)}, false
+	}
+	if ok {
+		return config.Violation{}, true
+	}
+
+	message := rule.Message
+	if message == "" {
+		message = fmt.Sprintf("rule %q failed", rule.Rule)
+	}
+	return config.Violation{Input: rule.Input, Message: message}, false
+}
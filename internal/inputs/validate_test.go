@@ -0,0 +1,104 @@
+package inputs
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func TestValidate_ReportsEveryViolation(t *testing.T) {
+	templating := &config.TemplatingConfig{
+		Inputs: config.Inputs{
+			{Name: "slug", Type: config.InputTypeString, Format: "kebab-case"},
+			{Name: "port", Type: config.InputTypeInt},
+			{Name: "name", Type: config.InputTypeString, Required: true},
+		},
+		Rules: []config.ValidationRule{
+			{Rule: `{{ ne .slug "admin" }}`, Message: "slug must not be admin"},
+		},
+	}
+	values := map[string]any{"slug": "Not_Kebab", "port": "eighty"}
+
+	err := Validate(templating, values)
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected *config.ValidationError, got %v", err)
+	}
+	if len(validationErr.Violations) != 3 {
+		t.Errorf("expected 3 violations, got %d: %v", len(validationErr.Violations), err)
+	}
+}
+
+func TestValidate_CoercesStoredValues(t *testing.T) {
+	templating := &config.TemplatingConfig{
+		Inputs: config.Inputs{
+			{Name: "port", Type: config.InputTypeInt},
+		},
+		Rules: []config.ValidationRule{
+			{Rule: `{{ gt .port 1024 }}`, Message: "port must be unprivileged"},
+		},
+	}
+	values := map[string]any{"port": "8080"}
+
+	if err := Validate(templating, values); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if values["port"] != 8080 {
+		t.Errorf("port = %#v, want 8080", values["port"])
+	}
+
+	values["port"] = 80
+	if err := Validate(templating, values); err == nil || !strings.Contains(err.Error(), "port must be unprivileged") {
+		t.Errorf("expected rule violation, got %v", err)
+	}
+}
+
+func TestCollect_ReasksWhenRuleFails(t *testing.T) {
+	templating := &config.TemplatingConfig{
+		Inputs: config.Inputs{
+			{Name: "name", Type: config.InputTypeString, Default: "app"},
+			{Name: "slug", Type: config.InputTypeString, Format: "kebab-case"},
+		},
+		Rules: []config.ValidationRule{
+			{Input: "slug", Rule: `{{ ne .slug .name }}`, Message: "slug must differ from name"},
+		},
+	}
+	// name is prompted first (accepts default), then slug: invalid format,
+	// violates the rule, then a valid answer.
+	in := strings.NewReader("\nNot Kebab\napp\napp-svc\n")
+	var out bytes.Buffer
+
+	values, err := Collect(templating, Options{Prompter: NewPrompter(in, &out)})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if values["slug"] != "app-svc" {
+		t.Errorf("slug = %v, want app-svc", values["slug"])
+	}
+	for _, want := range []string{"must be kebab-case", "slug must differ from name"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output, got %q", want, out.String())
+		}
+	}
+}
+
+func TestCollect_QuietFailsWithAllViolations(t *testing.T) {
+	templating := &config.TemplatingConfig{
+		Inputs: config.Inputs{
+			{Name: "owner", Type: config.InputTypeString, Required: true},
+			{Name: "slug", Type: config.InputTypeString, Format: "kebab-case", Default: "Bad Slug"},
+		},
+	}
+
+	_, err := Collect(templating, Options{})
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected *config.ValidationError, got %v", err)
+	}
+	if len(validationErr.Violations) != 2 {
+		t.Errorf("expected 2 violations, got %v", err)
+	}
+}
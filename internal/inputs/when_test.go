@@ -0,0 +1,87 @@
+package inputs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/faradayfan/sygkro/internal/config"
+)
+
+func whenTemplating() *config.TemplatingConfig {
+	return &config.TemplatingConfig{
+		Inputs: config.Inputs{
+			{Name: "use_docker", Type: config.InputTypeBool, Default: false},
+			{Name: "docker_registry", Type: config.InputTypeString, Required: true, When: ".use_docker"},
+			{Name: "replicas", Type: config.InputTypeInt, Default: 3, When: `{{ eq .env "prod" }}`},
+			{Name: "env", Type: config.InputTypeChoice, Choices: []string{"dev", "prod"}, Default: "dev"},
+		},
+		Rules: []config.ValidationRule{
+			{Input: "docker_registry", Rule: `ne .docker_registry "docker.io"`, Message: "use the internal registry"},
+		},
+	}
+}
+
+func TestCollect_SkipsDisabledInputs(t *testing.T) {
+	values, err := Collect(whenTemplating(), Options{})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	if _, ok := values["docker_registry"]; ok {
+		t.Error("docker_registry should be left out when use_docker is false")
+	}
+	if _, ok := values["replicas"]; ok {
+		t.Error("replicas should be left out when env is dev")
+	}
+	if values["use_docker"] != false || values["env"] != "dev" {
+		t.Errorf("unexpected values: %v", values)
+	}
+}
+
+func TestCollect_DerivedDefaultOfDisabledInputRendersEmpty(t *testing.T) {
+	templating := whenTemplating()
+	templating.Inputs = append(templating.Inputs, &config.InputSpec{
+		Name: "image", Type: config.InputTypeString, Default: "{{ .docker_registry }}/app",
+	})
+
+	values, err := Collect(templating, Options{})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if values["image"] != "/app" {
+		t.Errorf("image = %q, want %q", values["image"], "/app")
+	}
+}
+
+func TestCollect_AsksEnabledInputsAfterTheirConditions(t *testing.T) {
+	// Order: use_docker, docker_registry (enabled by yes), env, replicas (enabled by prod).
+	in := strings.NewReader("yes\ndocker.io\nregistry.acme.internal\nprod\n\n")
+	var out bytes.Buffer
+
+	values, err := Collect(whenTemplating(), Options{Prompter: NewPrompter(in, &out)})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	if values["replicas"] != 3 {
+		t.Errorf("replicas = %v, want 3", values["replicas"])
+	}
+	if values["docker_registry"] != "registry.acme.internal" {
+		t.Errorf("docker_registry = %v", values["docker_registry"])
+	}
+	if !strings.Contains(out.String(), "use the internal registry") {
+		t.Errorf("expected rule violation to be reported, got %q", out.String())
+	}
+}
+
+func TestValidate_DropsDisabledStoredInputs(t *testing.T) {
+	values := map[string]any{"use_docker": false, "env": "dev", "docker_registry": "docker.io"}
+
+	if err := Validate(whenTemplating(), values); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if _, ok := values["docker_registry"]; ok {
+		t.Error("disabled input should be removed from the values")
+	}
+}
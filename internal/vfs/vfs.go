@@ -0,0 +1,260 @@
+// Package vfs reads and writes rendered templates and projects through
+// go-billy filesystems, so that renders compared by diff and sync can stay in
+// memory while the project is read from and written to disk.
+package vfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-billy/v5/util"
+)
+
+// Memory returns an empty in-memory filesystem. Unlike memfs itself, it is
+// safe for use by several goroutines at once.
+func Memory() billy.Filesystem {
+	return &memory{Filesystem: memfs.New()}
+}
+
+// memory serializes the calls to a memfs filesystem, whose directory tree is
+// not safe for concurrent use. Files are written through their own handles,
+// which lock their content.
+type memory struct {
+	billy.Filesystem
+	mu sync.Mutex
+}
+
+func (m *memory) Create(filename string) (billy.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Filesystem.Create(filename)
+}
+
+func (m *memory) Open(filename string) (billy.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Filesystem.Open(filename)
+}
+
+func (m *memory) OpenFile(filename string, flag int, perm os.FileMode) (billy.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Filesystem.OpenFile(filename, flag, perm)
+}
+
+func (m *memory) Stat(filename string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Filesystem.Stat(filename)
+}
+
+func (m *memory) Lstat(filename string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Filesystem.Lstat(filename)
+}
+
+func (m *memory) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Filesystem.Rename(oldpath, newpath)
+}
+
+func (m *memory) Remove(filename string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Filesystem.Remove(filename)
+}
+
+func (m *memory) ReadDir(path string) ([]os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Filesystem.ReadDir(path)
+}
+
+func (m *memory) MkdirAll(filename string, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Filesystem.MkdirAll(filename, perm)
+}
+
+func (m *memory) Symlink(target, link string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Filesystem.Symlink(target, link)
+}
+
+func (m *memory) Readlink(link string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Filesystem.Readlink(link)
+}
+
+// OS returns the filesystem of the directory dir on disk.
+func OS(dir string) billy.Filesystem {
+	return &disk{Filesystem: osfs.New(dir), dir: dir}
+}
+
+// disk is an osfs filesystem that can change permissions, which go-billy
+// has no interface for, and that keeps absolute symlink targets as they are
+// instead of placing them under dir.
+type disk struct {
+	billy.Filesystem
+	dir string
+}
+
+// Chmod changes the permissions of the file at path.
+func (d *disk) Chmod(path string, mode os.FileMode) error {
+	return os.Chmod(filepath.Join(d.dir, path), mode)
+}
+
+func (d *disk) Symlink(target, link string) error {
+	path := filepath.Join(d.dir, link)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.Symlink(target, path)
+}
+
+func (d *disk) Readlink(link string) (string, error) {
+	return os.Readlink(filepath.Join(d.dir, link))
+}
+
+// chmoder is a filesystem that can change permissions in place.
+type chmoder interface {
+	Chmod(path string, mode os.FileMode) error
+}
+
+// ReadFile returns the content of the file at path.
+func ReadFile(fs billy.Filesystem, path string) ([]byte, error) {
+	return util.ReadFile(fs, path)
+}
+
+// WriteFile writes content to path and gives it the permissions of mode,
+// also when the file already exists, so that executable bits survive. A
+// symlink at path is replaced rather than followed.
+func WriteFile(fs billy.Filesystem, path string, content []byte, mode os.FileMode) error {
+	mode = mode.Perm()
+	_, canChmod := fs.(chmoder)
+
+	// In memory the permissions of a file are those it was created with
+	if info, err := fs.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSymlink != 0 || !canChmod && info.Mode() != mode {
+			if err := fs.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	f, err := fs.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if canChmod {
+		return fs.(chmoder).Chmod(path, mode)
+	}
+	return nil
+}
+
+// WriteSymlink creates a symlink at path to target, replacing a file at path.
+func WriteSymlink(fs billy.Filesystem, path, target string) error {
+	if err := fs.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return fs.Symlink(target, path)
+}
+
+// IsSymlink reports whether path is a symlink.
+func IsSymlink(fs billy.Filesystem, path string) bool {
+	info, err := fs.Lstat(path)
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
+
+// Exists reports whether a file or symlink, not a directory, is at path.
+func Exists(fs billy.Filesystem, path string) bool {
+	info, err := fs.Lstat(path)
+	return err == nil && !info.IsDir()
+}
+
+// FileMode returns the permissions of a regular file, or 0 for a symlink or
+// a missing file.
+func FileMode(fs billy.Filesystem, path string) os.FileMode {
+	info, err := fs.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return 0
+	}
+	return info.Mode().Perm()
+}
+
+// ReadContent returns the content of a file, or the target of a symlink.
+func ReadContent(fs billy.Filesystem, path string) ([]byte, error) {
+	if IsSymlink(fs, path) {
+		target, err := fs.Readlink(path)
+		return []byte(target), err
+	}
+	return ReadFile(fs, path)
+}
+
+// CopyFile copies the file or symlink at srcPath in src to dstPath in dst,
+// replacing what is there. A copied file gets the given permissions.
+func CopyFile(src billy.Filesystem, srcPath string, dst billy.Filesystem, dstPath string, mode os.FileMode) error {
+	if IsSymlink(src, srcPath) {
+		target, err := src.Readlink(srcPath)
+		if err != nil {
+			return err
+		}
+		return WriteSymlink(dst, dstPath, target)
+	}
+
+	content, err := ReadFile(src, srcPath)
+	if err != nil {
+		return err
+	}
+	return WriteFile(dst, dstPath, content, mode)
+}
+
+// Move moves the file or symlink at from to to. In memory the file is
+// copied and removed, as memfs also moves the files whose paths merely start
+// with from.
+func Move(fs billy.Filesystem, from, to string) error {
+	if _, ok := fs.(*disk); ok {
+		return fs.Rename(from, to)
+	}
+	if err := CopyFile(fs, from, fs, to, FileMode(fs, from)); err != nil {
+		return err
+	}
+	return fs.Remove(from)
+}
+
+// Files returns the paths of the files and symlinks in fs, relative to its
+// root. A filesystem of a directory that does not exist has no files.
+func Files(fs billy.Filesystem) (map[string]bool, error) {
+	files := make(map[string]bool)
+	if _, err := fs.Lstat("."); errors.Is(err, os.ErrNotExist) {
+		return files, nil
+	}
+
+	err := util.Walk(fs, ".", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			files[filepath.Clean(path)] = true
+		}
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,142 @@
+package vfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/go-git/go-billy/v5"
+)
+
+func TestWriteFile_Modes(t *testing.T) {
+	for name, fs := range map[string]billy.Filesystem{"memory": Memory(), "disk": OS(t.TempDir())} {
+		t.Run(name, func(t *testing.T) {
+			if err := WriteFile(fs, "bin/run.sh", []byte("echo old\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := WriteFile(fs, "bin/run.sh", []byte("echo new\n"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			if mode := FileMode(fs, "bin/run.sh"); mode != 0755 {
+				t.Errorf("mode = %04o, want 0755", mode)
+			}
+			data, err := ReadFile(fs, "bin/run.sh")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != "echo new\n" {
+				t.Errorf("content = %q", data)
+			}
+		})
+	}
+}
+
+func TestWriteFile_ReplacesSymlink(t *testing.T) {
+	for name, fs := range map[string]billy.Filesystem{"memory": Memory(), "disk": OS(t.TempDir())} {
+		t.Run(name, func(t *testing.T) {
+			if err := WriteFile(fs, "target.md", []byte("target\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := WriteSymlink(fs, "README.md", "target.md"); err != nil {
+				t.Fatal(err)
+			}
+			if !IsSymlink(fs, "README.md") {
+				t.Fatal("expected README.md to be a symlink")
+			}
+			if content, err := ReadContent(fs, "README.md"); err != nil || string(content) != "target.md" {
+				t.Errorf("ReadContent = %q, %v, want the link target", content, err)
+			}
+
+			if err := WriteFile(fs, "README.md", []byte("readme\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if IsSymlink(fs, "README.md") {
+				t.Error("expected the symlink to be replaced")
+			}
+			if data, _ := ReadFile(fs, "target.md"); string(data) != "target\n" {
+				t.Errorf("symlink target was written through: %q", data)
+			}
+		})
+	}
+}
+
+func TestOS_KeepsAbsoluteSymlinkTargets(t *testing.T) {
+	dir := t.TempDir()
+	fs := OS(dir)
+	if err := WriteSymlink(fs, "link", "/etc/hosts"); err != nil {
+		t.Fatal(err)
+	}
+	target, err := os.Readlink(filepath.Join(dir, "link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target != "/etc/hosts" {
+		t.Errorf("link -> %s, want /etc/hosts", target)
+	}
+}
+
+func TestMove_InMemoryLeavesPrefixedPaths(t *testing.T) {
+	fs := Memory()
+	for _, path := range []string{"app.go", "app.go.orig"} {
+		if err := WriteFile(fs, path, []byte(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Move(fs, "app.go", "main.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := Files(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"main.go": true, "app.go.orig": true}; !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+	if mode := FileMode(fs, "main.go"); mode != 0755 {
+		t.Errorf("mode = %04o, want 0755", mode)
+	}
+}
+
+func TestMemory_ConcurrentWrites(t *testing.T) {
+	fs := Memory()
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path := filepath.Join(fmt.Sprintf("dir%d", i%5), fmt.Sprintf("file%d.txt", i))
+			if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := WriteFile(fs, path, []byte(path), 0644); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	files, err := Files(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 50 {
+		t.Errorf("expected 50 files, got %d", len(files))
+	}
+}
+
+func TestFiles_MissingDirectory(t *testing.T) {
+	files, err := Files(OS(filepath.Join(t.TempDir(), "missing")))
+	if err != nil {
+		t.Fatalf("Files failed: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("expected no files, got %v", files)
+	}
+}